@@ -1,9 +1,12 @@
 package online
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -14,27 +17,56 @@ import (
 
 type responseType int
 
-const (
-	serverEndPoint = "https://api.online.net/api/v1/server"
-	rpnv2EndPoint  = "https://api.online.net/api/v1/rpn/v2"
+// DefaultBaseURL is the base URL of the Online.net API used when none is
+// given to NewClient.
+const DefaultBaseURL = "https://api.online.net"
+
+// listServersWorkers is the number of servers fetched concurrently by
+// ListServers
+const listServersWorkers = 4
 
+const (
 	responseBoolean responseType = iota
 	responseJSON
 	responseString
 )
 
 type Client interface {
+	// WithContext returns a Client sending its requests with the given
+	// context, cancelling it aborts any request or wait in progress.
+	WithContext(ctx context.Context) Client
+	// Context returns the context of the requests sent by the Client.
+	Context() context.Context
+
 	Server(id int) (*Server, error)
+	ListServers() ([]*Server, error)
 	SetServer(s *Server) error
 
+	OperatingSystems(serverID int) ([]*OperatingSystem, error)
+	InstallServer(serverID int, i *Install, wait time.Duration) error
+	InstallStatus(serverID int) (string, error)
+
+	ListPartitioningTemplates() ([]*PartitioningTemplate, error)
+	PartitioningTemplate(id int) (*PartitioningTemplate, error)
+	SetPartitioningTemplate(t *PartitioningTemplate) error
+	DeletePartitioningTemplate(id int) error
+
+	BootServer(serverID int, wait time.Duration) error
+	ShutdownServer(serverID int, reason string, wait time.Duration) error
+	RebootServer(serverID int, reason string, wait time.Duration) error
+
 	BootRescueMode(serverID int, image string) (*RescueCredentials, error)
 	BootNormalMode(serverID int) error
+	WaitBootMode(serverID int, mode string, wait time.Duration) error
 
 	GetRescueImages(serverID int) ([]string, error)
 
+	ListFailoverIPs() ([]*FailoverIP, error)
 	EditFailoverIP(source, destination string) error
+	MoveFailoverIPs(ips []string, destination string, pacing, wait time.Duration) error
 	GenerateMACFailoverIP(address, macType string) (string, error)
 	DeleteMACFailoverIP(address string) error
+	SetFailoverIPReverse(address, reverse string) error
 
 	ListRPNv2() ([]*RPNv2, error)
 	RPNv2(id int) (*RPNv2, error)
@@ -43,35 +75,96 @@ type Client interface {
 	DeleteRPNv2(id int, wait time.Duration) error
 }
 
-func NewClient(token string) Client {
-	return &client{token: token, c: &http.Client{}}
+// Options configures a Client, the zero value is valid.
+type Options struct {
+	// BaseURL is the URL of the API, every endpoint is derived from it.
+	// Defaults to DefaultBaseURL.
+	BaseURL string
+	// Retry is the policy used to retry requests failing with a transient
+	// error. Defaults to DefaultRetryPolicy.
+	Retry *RetryPolicy
+}
+
+// NewClient returns a Client authenticated with the given token, opts may be
+// nil to use the defaults.
+func NewClient(token string, opts *Options) Client {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	baseURL := strings.TrimRight(opts.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	retry := opts.Retry
+	if retry == nil {
+		retry = &DefaultRetryPolicy
+	}
+
+	return &client{
+		ctx:            context.Background(),
+		token:          token,
+		c:              &http.Client{},
+		retry:          *retry,
+		rpnWriteLock:   &sync.Mutex{},
+		serverEndPoint: baseURL + "/api/v1/server",
+		rpnv2EndPoint:  baseURL + "/api/v1/rpn/v2",
+	}
 }
 
 type client struct {
+	ctx   context.Context
 	token string
 	c     *http.Client
+	retry RetryPolicy
+
+	serverEndPoint string
+	rpnv2EndPoint  string
 
-	// rpn changes are controlled by a mutex
-	rpnWriteLock sync.Mutex
+	// rpn changes are controlled by a mutex, shared by every client
+	// returned by WithContext
+	rpnWriteLock *sync.Mutex
+}
+
+func (c *client) WithContext(ctx context.Context) Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+func (c *client) Context() context.Context {
+	return c.ctx
 }
 
 func (c *client) Do(req *http.Request) (*http.Response, error) {
+	req = req.WithContext(c.ctx)
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.token))
 	return c.c.Do(req)
 }
 
-func (c *client) doGET(target string) ([]byte, error) {
-	req, err := http.NewRequest("GET", target, nil)
-	if err != nil {
-		return nil, err
-	}
+// sleep waits for the given duration, returning early with the context error
+// if the client context is cancelled.
+func (c *client) sleep(d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
 
-	res, err := c.Do(req)
-	if err != nil {
-		return nil, err
+	select {
+	case <-t.C:
+		return nil
+	case <-c.ctx.Done():
+		return c.ctx.Err()
 	}
+}
 
-	return c.handleResponse(res)
+func (c *client) doGET(target string) ([]byte, error) {
+	return c.doWithRetry("GET", func() (*http.Request, error) {
+		return http.NewRequest("GET", target, nil)
+	})
 }
 
 func (c *client) doPUT(target string, values map[string]string) ([]byte, error) {
@@ -96,19 +189,63 @@ func (c *client) doRequest(method, target string, values map[string]string) ([]b
 		form.Add(k, v)
 	}
 
-	req, err := http.NewRequest(method, target, strings.NewReader(form.Encode()))
-	if err != nil {
-		return nil, err
-	}
+	body := form.Encode()
+	return c.doWithRetry(method, func() (*http.Request, error) {
+		req, err := http.NewRequest(method, target, strings.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
 
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	})
+}
 
-	resp, err := c.Do(req)
-	if err != nil {
-		return nil, err
-	}
+// doWithRetry sends the request built by newRequest, sending a fresh one
+// every time the answer is a transient error allowed by the retry policy.
+func (c *client) doWithRetry(method string, newRequest func() (*http.Request, error)) ([]byte, error) {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := c.Do(req)
+		if ctxErr := c.ctx.Err(); ctxErr != nil {
+			if err == nil {
+				res.Body.Close()
+			}
+
+			return nil, ctxErr
+		}
+
+		delay, retry := c.retry.next(attempt, time.Since(start), method, res, err)
+		if !retry {
+			if err != nil {
+				return nil, err
+			}
+
+			return c.handleResponse(res)
+		}
+
+		if err != nil {
+			log.Printf("[DEBUG] %s %s failed: %s, retrying in %s", method, req.URL, err, delay)
+		} else {
+			log.Printf("[DEBUG] %s %s answered %s, retrying in %s", method, req.URL, res.Status, delay)
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if c.retry.sleep != nil {
+			c.retry.sleep(delay)
+			continue
+		}
 
-	return c.handleResponse(resp)
+		if err := c.sleep(delay); err != nil {
+			return nil, err
+		}
+	}
 }
 
 func (c *client) handleResponse(r *http.Response) ([]byte, error) {
@@ -122,12 +259,12 @@ func (c *client) handleResponse(r *http.Response) ([]byte, error) {
 		return body, nil
 	}
 
-	return nil, decodeErrorResponse(body)
+	return nil, decodeErrorResponse(r.StatusCode, r.Request.URL.Path, body)
 }
 
 func (c *client) SetServer(s *Server) error {
 
-	target := fmt.Sprintf("%s/%d", serverEndPoint, s.ID)
+	target := fmt.Sprintf("%s/%d", c.serverEndPoint, s.ID)
 	_, err := c.doPUT(target, map[string]string{
 		"hostname": s.Hostname,
 	})
@@ -145,7 +282,7 @@ func (c *client) SetServer(s *Server) error {
 }
 
 func (c *client) doSetServerIP(i *Interface) error {
-	target := fmt.Sprintf("%s/ip/edit", serverEndPoint)
+	target := fmt.Sprintf("%s/ip/edit", c.serverEndPoint)
 	_, err := c.doPOST(target, map[string]string{
 		"address": i.Address,
 		"reverse": i.Reverse,
@@ -155,7 +292,7 @@ func (c *client) doSetServerIP(i *Interface) error {
 }
 
 func (c *client) Server(id int) (*Server, error) {
-	target := fmt.Sprintf("%s/%d", serverEndPoint, id)
+	target := fmt.Sprintf("%s/%d", c.serverEndPoint, id)
 	js, err := c.doGET(target)
 	if err != nil {
 		return nil, err
@@ -165,8 +302,97 @@ func (c *client) Server(id int) (*Server, error) {
 	return s, json.Unmarshal(js, s)
 }
 
+// ListServers returns every server of the account, their details are fetched
+// concurrently and returned in the order given by the API.
+func (c *client) ListServers() ([]*Server, error) {
+	ids, err := c.listServerIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+	wc := c.WithContext(ctx)
+
+	servers := make([]*Server, len(ids))
+	errs := make([]error, len(ids))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < listServersWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				servers[j], errs[j] = wc.Server(ids[j])
+				if errs[j] != nil {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	for j := range ids {
+		jobs <- j
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, err := range errs {
+		if err != nil && err != context.Canceled {
+			return nil, err
+		}
+	}
+
+	return servers, nil
+}
+
+// listServerIDs returns the ids of the servers linked by /server, given
+// either as plain paths or as {"$ref": path} objects.
+func (c *client) listServerIDs() ([]int, error) {
+	js, err := c.doGET(c.serverEndPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []json.RawMessage
+	if err := json.Unmarshal(js, &refs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(refs))
+	for _, raw := range refs {
+		var ref string
+		if err := json.Unmarshal(raw, &ref); err != nil {
+			obj := struct {
+				Ref string `json:"$ref"`
+			}{}
+
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				return nil, err
+			}
+
+			ref = obj.Ref
+		}
+
+		id, err := strconv.Atoi(ref[strings.LastIndex(ref, "/")+1:])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected server reference: %q", ref)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
 func (c *client) ListRPNv2() ([]*RPNv2, error) {
-	js, err := c.doGET(rpnv2EndPoint)
+	js, err := c.doGET(c.rpnv2EndPoint)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +402,7 @@ func (c *client) ListRPNv2() ([]*RPNv2, error) {
 }
 
 func (c *client) RPNv2(id int) (*RPNv2, error) {
-	target := fmt.Sprintf("%s/%d", rpnv2EndPoint, id)
+	target := fmt.Sprintf("%s/%d", c.rpnv2EndPoint, id)
 	js, err := c.doGET(target)
 	if err != nil {
 		return nil, err
@@ -216,7 +442,13 @@ func (c *client) SetRPNv2(r *RPNv2, wait time.Duration) error {
 		return err
 	}
 
-	return c.doSyncVLAN(r, wait)
+	if r.Type != QinQ {
+		if err := c.doSyncVLAN(r, wait); err != nil {
+			return err
+		}
+	}
+
+	return c.doSyncCompatibility(r, wait)
 }
 
 func (c *client) doCreateRPNv2(r *RPNv2, wait time.Duration) error {
@@ -226,7 +458,7 @@ func (c *client) doCreateRPNv2(r *RPNv2, wait time.Duration) error {
 	}
 
 	idsJSON, _ := json.Marshal(ids)
-	js, err := c.doPOST(rpnv2EndPoint, map[string]string{
+	js, err := c.doPOST(c.rpnv2EndPoint, map[string]string{
 		"type":        string(r.Type),
 		"description": r.Name,
 		"server_ids":  string(idsJSON),
@@ -236,10 +468,13 @@ func (c *client) doCreateRPNv2(r *RPNv2, wait time.Duration) error {
 		return err
 	}
 
-	if err := json.Unmarshal(js, r); err != nil {
+	// the answer has the members with the default VLAN, keep the wanted ones
+	created := &RPNv2{}
+	if err := json.Unmarshal(js, created); err != nil {
 		return err
 	}
 
+	r.ID = created.ID
 	return c.waitRPNv2(r.ID, wait)
 }
 
@@ -253,6 +488,12 @@ func (c *client) doUpdateRPNv2(r *RPNv2, wait time.Duration) error {
 		return fmt.Errorf("rpn type can't changed after creation")
 	}
 
+	if r.Name != prev.Name {
+		if err := c.doRenameRPNv2(r); err != nil {
+			return err
+		}
+	}
+
 	var toAdd []int
 	for _, new := range r.Members {
 		if prev.MemberByServerID(new.Linked.ID) != nil {
@@ -286,12 +527,22 @@ func (c *client) doUpdateRPNv2(r *RPNv2, wait time.Duration) error {
 	return c.waitRPNv2(r.ID, wait)
 }
 
+// doRenameRPNv2 sets the description of the group to its name.
+func (c *client) doRenameRPNv2(r *RPNv2) error {
+	target := fmt.Sprintf("%s/%d", c.rpnv2EndPoint, r.ID)
+	_, err := c.doPATCH(target, map[string]string{
+		"description": r.Name,
+	})
+
+	return err
+}
+
 func (c *client) doAddMembers(r *RPNv2, serverIDs []int) error {
 	if len(serverIDs) == 0 {
 		return nil
 	}
 
-	target := fmt.Sprintf("%s/%d/addMember", rpnv2EndPoint, r.ID)
+	target := fmt.Sprintf("%s/%d/addMember", c.rpnv2EndPoint, r.ID)
 	idsJSON, _ := json.Marshal(serverIDs)
 	_, err := c.doPOST(target, map[string]string{
 		"server_ids": string(idsJSON),
@@ -305,7 +556,7 @@ func (c *client) doRemoveMembers(r *RPNv2, serverIDs []int) error {
 		return nil
 	}
 
-	target := fmt.Sprintf("%s/%d/removeMember", rpnv2EndPoint, r.ID)
+	target := fmt.Sprintf("%s/%d/removeMember", c.rpnv2EndPoint, r.ID)
 	idsJSON, _ := json.Marshal(serverIDs)
 	_, err := c.doDELETE(target, map[string]string{
 		"server_ids": string(idsJSON),
@@ -341,8 +592,34 @@ func (c *client) doSyncVLAN(r *RPNv2, wait time.Duration) error {
 	return nil
 }
 
+// doSyncCompatibility enables or disables the RPNv1 compatibility of the group
+// when it differs.
+func (c *client) doSyncCompatibility(r *RPNv2, wait time.Duration) error {
+	prev, err := c.RPNv2(r.ID)
+	if err != nil {
+		return err
+	}
+
+	if prev.CompatibilityRPNv1 == r.CompatibilityRPNv1 {
+		return nil
+	}
+
+	target := fmt.Sprintf("%s/%d/compatibility", c.rpnv2EndPoint, r.ID)
+	if r.CompatibilityRPNv1 {
+		_, err = c.doPOST(target, nil)
+	} else {
+		_, err = c.doDELETE(target, nil)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.waitRPNv2(r.ID, wait)
+}
+
 func (c *client) doEditVlanMember(groupID int, m *Member) error {
-	target := fmt.Sprintf("%s/%d/editVlanMember/%d", rpnv2EndPoint, groupID, m.ID)
+	target := fmt.Sprintf("%s/%d/editVlanMember/%d", c.rpnv2EndPoint, groupID, m.ID)
 	_, err := c.doPATCH(target, map[string]string{
 		"vlan_number": strconv.Itoa(m.VLAN),
 		"reset_vlan":  "false",
@@ -351,101 +628,27 @@ func (c *client) doEditVlanMember(groupID int, m *Member) error {
 	return err
 }
 
-func (c *client) waitRPNv2(id int, wait time.Duration) error {
-	until := time.Now().Add(wait)
+// rpnv2PollInterval is the interval between two checks of a RPNv2 group status
+const rpnv2PollInterval = time.Second
 
-	for now := range time.Tick(time.Second) {
-		rpn, err := c.RPNv2(id)
-		if err != nil {
-			return err
-		}
-
-		membersUpdating := false
-		for _, m := range rpn.Members {
-			if m.Status != "ACTIVE" {
-				membersUpdating = true
-			}
-		}
-
-		if !membersUpdating && rpn.Status == "ACTIVE" {
-			return nil
-		}
-
-		if now.After(until) {
-			return fmt.Errorf("timeout waiting for RPNv2 changes")
-		}
-	}
-
-	return nil
+func (c *client) waitRPNv2(id int, wait time.Duration) error {
+	return c.waitFor("RPNv2 changes", rpnv2PollInterval, wait, RPNv2Active(c, id))
 }
 
 func (c *client) DeleteRPNv2(id int, wait time.Duration) error {
 	c.rpnWriteLock.Lock()
 	defer c.rpnWriteLock.Unlock()
 
-	target := fmt.Sprintf("%s/%d", rpnv2EndPoint, id)
+	target := fmt.Sprintf("%s/%d", c.rpnv2EndPoint, id)
 	_, err := c.doDELETE(target, nil)
 	if err != nil {
 		return err
 	}
 
 	err = c.waitRPNv2(id, wait)
-	if err == nil {
+	if err == nil || IsNotFound(err) {
 		return nil
 	}
 
-	if er, ok := err.(*ErrorResponse); ok {
-		// 7 is the code of RPNv2 not found error
-		if er.Code == 7 {
-			return nil
-		}
-	}
-
 	return err
-
-}
-
-type ErrorResponse struct {
-	Code    int
-	Message string `json:"error"`
-}
-
-func decodeErrorResponse(b []byte) error {
-	e := &ErrorResponse{}
-
-	values := map[string]interface{}{}
-	err := json.Unmarshal(b, &values)
-	if err != nil {
-		goto Unexpected
-	}
-
-	if msg, ok := values["error"]; ok {
-		if e.Message, ok = msg.(string); !ok {
-			goto Unexpected
-		}
-	}
-
-	if msg, ok := values["error_description"]; ok {
-		if e.Message, ok = msg.(string); !ok {
-			goto Unexpected
-		}
-	}
-
-	if code, ok := values["code"]; ok {
-		code, ok := code.(float64)
-		if !ok {
-			goto Unexpected
-		}
-
-		e.Code = int(code)
-	}
-
-	return e
-
-Unexpected:
-	return fmt.Errorf("unexpected answer from server: %s", b)
-}
-
-func (e *ErrorResponse) Error() string {
-	return fmt.Sprintf("%s (code: %d)", e.Message, e.Code)
 }
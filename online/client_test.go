@@ -0,0 +1,453 @@
+package online
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:     3,
+	InitialInterval: time.Millisecond,
+	MaxInterval:     10 * time.Millisecond,
+}
+
+func newTestServer(statuses ...int) (*httptest.Server, *int) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[len(statuses)-1]
+		if calls < len(statuses) {
+			status = statuses[calls]
+		}
+
+		calls++
+		w.WriteHeader(status)
+		if status >= 300 {
+			w.Write([]byte(`{"error": "failed", "code": 1}`))
+			return
+		}
+
+		w.Write([]byte(`"ok"`))
+	}))
+
+	return srv, &calls
+}
+
+func TestClientRetryTransientErrors(t *testing.T) {
+	srv, calls := newTestServer(http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+	defer srv.Close()
+
+	c := NewClient("token", &Options{Retry: &testRetryPolicy}).(*client)
+	body, err := c.doGET(srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, `"ok"`, string(body))
+	assert.Equal(t, 3, *calls)
+}
+
+func TestClientRetryMaxAttempts(t *testing.T) {
+	srv, calls := newTestServer(http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	c := NewClient("token", &Options{Retry: &testRetryPolicy}).(*client)
+	_, err := c.doGET(srv.URL)
+	assert.EqualError(t, err, "failed (code: 1)")
+	assert.Equal(t, 3, *calls)
+}
+
+func TestClientRetryNonIdempotent(t *testing.T) {
+	srv, calls := newTestServer(http.StatusServiceUnavailable, http.StatusOK)
+	defer srv.Close()
+
+	c := NewClient("token", &Options{Retry: &testRetryPolicy}).(*client)
+	_, err := c.doPOST(srv.URL, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, *calls)
+
+	// a 429 is only retried when the API tells when to come back
+	srv, calls = newTestServer(http.StatusTooManyRequests, http.StatusOK)
+	defer srv.Close()
+
+	_, err = c.doPOST(srv.URL, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, *calls)
+
+	var retryAfterCalls int
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		retryAfterCalls++
+		if retryAfterCalls == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Write([]byte(`"ok"`))
+	}))
+	defer srv.Close()
+
+	policy := testRetryPolicy
+	policy.sleep = func(time.Duration) {}
+
+	c = NewClient("token", &Options{Retry: &policy}).(*client)
+	_, err = c.doPOST(srv.URL, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, retryAfterCalls)
+}
+
+func TestClientRetryPATCH(t *testing.T) {
+	srv, calls := newTestServer(http.StatusServiceUnavailable, http.StatusOK)
+	defer srv.Close()
+
+	c := NewClient("token", &Options{Retry: &testRetryPolicy}).(*client)
+	_, err := c.doPATCH(srv.URL, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, *calls)
+}
+
+func TestClientRetryClientErrors(t *testing.T) {
+	srv, calls := newTestServer(http.StatusNotFound, http.StatusOK)
+	defer srv.Close()
+
+	c := NewClient("token", &Options{Retry: &testRetryPolicy}).(*client)
+	_, err := c.doGET(srv.URL)
+	assert.Error(t, err)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Write([]byte(`"ok"`))
+	}))
+	defer srv.Close()
+
+	var delays []time.Duration
+	policy := testRetryPolicy
+	policy.sleep = func(d time.Duration) {
+		delays = append(delays, d)
+	}
+
+	c := NewClient("token", &Options{Retry: &policy}).(*client)
+	_, err := c.doGET(srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, []time.Duration{time.Second}, delays)
+}
+
+func TestClientRetryMaxElapsedTime(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	policy := testRetryPolicy
+	policy.MaxElapsedTime = time.Second
+
+	c := NewClient("token", &Options{Retry: &policy}).(*client)
+	_, err := c.doGET(srv.URL)
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2019, 5, 27, 10, 0, 0, 0, time.UTC)
+
+	d, ok := parseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, d)
+
+	d, ok = parseRetryAfter("Mon, 27 May 2019 10:00:30 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, d)
+
+	_, ok = parseRetryAfter("", now)
+	assert.False(t, ok)
+
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestClientBaseURL(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{"id": 42, "hostname": "foo"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("token", &Options{BaseURL: srv.URL + "/proxy/"})
+	s, err := c.Server(42)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", s.Hostname)
+	assert.Equal(t, "/proxy/api/v1/server/42", path)
+
+	c = NewClient("token", nil)
+	assert.Equal(t, DefaultBaseURL+"/api/v1/rpn/v2", c.(*client).rpnv2EndPoint)
+}
+
+func TestClientContextCancelRequest(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewClient("token", &Options{BaseURL: srv.URL}).WithContext(ctx)
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := c.Server(42)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestClientContextCancelWait(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 42, "status": "UPDATING"}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewClient("token", &Options{BaseURL: srv.URL}).WithContext(ctx).(*client)
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := c.waitRPNv2(42, time.Hour)
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestClientListServers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/server", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`["/api/v1/server/1", {"$ref": "/api/v1/server/2"}, "/api/v1/server/3"]`))
+	})
+	mux.HandleFunc("/api/v1/server/", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/api/v1/server/"):]
+		w.Write([]byte(`{"id": ` + id + `, "hostname": "host-` + id + `"}`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	servers, err := c.ListServers()
+	assert.NoError(t, err)
+	if assert.Len(t, servers, 3) {
+		for i, s := range servers {
+			assert.Equal(t, i+1, s.ID)
+			assert.Equal(t, fmt.Sprintf("host-%d", i+1), s.Hostname)
+		}
+	}
+}
+
+func TestClientListServersError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/server", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`["/api/v1/server/1", "/api/v1/server/2"]`))
+	})
+	mux.HandleFunc("/api/v1/server/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "Server not found", "code": 3}`))
+	})
+	mux.HandleFunc("/api/v1/server/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 1}`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	_, err := c.ListServers()
+	assert.True(t, IsNotFound(err))
+}
+
+func TestClientInstallServer(t *testing.T) {
+	var form map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/v1/server/install/42", r.URL.Path)
+		r.ParseForm()
+		form = r.PostForm
+		w.Write([]byte(`true`))
+	}))
+	defer srv.Close()
+
+	install := &Install{
+		OSID:         300,
+		Hostname:     "web",
+		RootPassword: "secret",
+		SSHKeys:      []string{"key-1", "key-2"},
+		Partitions: []*Partition{
+			{FileSystem: "ext4", MountPoint: "/", Size: 20000},
+			{DriveArray: 1, FileSystem: "swap", RaidLevel: "raid1"},
+		},
+	}
+
+	// the installation is not waited for, the deadline expires first
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+
+	c := NewClient("token", &Options{BaseURL: srv.URL}).WithContext(ctx)
+	err := c.InstallServer(42, install, time.Hour)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, map[string][]string{
+		"os_id":         {"300"},
+		"hostname":      {"web"},
+		"root_password": {"secret"},
+		"ssh_keys":      {`["key-1","key-2"]`},
+		"partitions": {`[{"drive_array":0,"mount_point":"/","file_system":"ext4","size":20000},` +
+			`{"drive_array":1,"raid_level":"raid1","file_system":"swap","size":0}]`},
+	}, form)
+}
+
+func TestClientInstallStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/server/install/status/42", r.URL.Path)
+		w.Write([]byte(`{"status": "installing", "progress": 42}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	status, err := c.InstallStatus(42)
+	assert.NoError(t, err)
+	assert.Equal(t, InstallStatusInstalling, status)
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`"installed"`))
+	})
+
+	_, err = c.InstallStatus(42)
+	assert.Error(t, err)
+}
+
+func TestClientListFailoverIPs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/server/failover", r.URL.Path)
+		w.Write([]byte(`[
+			{"source": "1.2.3.4", "destination": "5.6.7.8", "mac": "52:54:00:aa:bb:cc", "reverse": "vip.example.com"},
+			{"source": "1.2.3.5", "destination": null, "mac": null, "reverse": null}
+		]`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	list, err := c.ListFailoverIPs()
+	assert.NoError(t, err)
+	assert.Equal(t, []*FailoverIP{
+		{Source: "1.2.3.4", Destination: "5.6.7.8", MAC: "52:54:00:aa:bb:cc", Reverse: "vip.example.com"},
+		{Source: "1.2.3.5"},
+	}, list)
+}
+
+func TestClientSetRPNv2Rename(t *testing.T) {
+	var renames []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/rpn/v2/42", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" {
+			renames = append(renames, r.FormValue("description"))
+			w.Write([]byte(`true`))
+			return
+		}
+
+		w.Write([]byte(`{"id": 42, "description": "old", "status": "ACTIVE", "type": "STANDARD",
+			"member": [{"id": 1, "linked": {"id": 7}, "status": "ACTIVE", "vlan": 2000}]}`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	r := &RPNv2{ID: 42, Name: "new", Type: Standard, Members: []*Member{{VLAN: 2000}}}
+	r.Members[0].Linked.ID = 7
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	assert.NoError(t, c.SetRPNv2(r, time.Minute))
+	assert.Equal(t, []string{"new"}, renames)
+}
+
+func TestClientSetRPNv2CreateVLAN(t *testing.T) {
+	vlans := map[string]string{"1": "0", "2": "0"}
+	compatibility := false
+	var edits []string
+	group := func() string {
+		return fmt.Sprintf(`{"id": 42, "description": "new", "status": "ACTIVE", "type": "STANDARD",
+			"compatibility_rpn_v1": %t,
+			"member": [{"id": 1, "linked": {"id": 7}, "status": "ACTIVE", "vlan": %s},
+			{"id": 2, "linked": {"id": 8}, "status": "ACTIVE", "vlan": %s}]}`, compatibility, vlans["1"], vlans["2"])
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/rpn/v2", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		w.Write([]byte(group()))
+	})
+	mux.HandleFunc("/api/v1/rpn/v2/42", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(group()))
+	})
+	mux.HandleFunc("/api/v1/rpn/v2/42/editVlanMember/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/rpn/v2/42/editVlanMember/")
+		vlans[id] = r.FormValue("vlan_number")
+		edits = append(edits, r.Method+" "+id+" "+vlans[id])
+		w.Write([]byte(`true`))
+	})
+	mux.HandleFunc("/api/v1/rpn/v2/42/compatibility", func(w http.ResponseWriter, r *http.Request) {
+		compatibility = r.Method == "POST"
+		w.Write([]byte(`true`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	r := &RPNv2{Name: "new", Type: Standard, CompatibilityRPNv1: true, Members: []*Member{{VLAN: 2000}, {VLAN: 2001}}}
+	r.Members[0].Linked.ID = 7
+	r.Members[1].Linked.ID = 8
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	assert.NoError(t, c.SetRPNv2(r, time.Minute))
+	assert.Equal(t, 42, r.ID)
+	assert.Equal(t, []string{"PATCH 1 2000", "PATCH 2 2001"}, edits)
+	assert.True(t, compatibility)
+}
+
+func TestClientSetRPNv2QinQCompatibility(t *testing.T) {
+	var calls []string
+	compatibility := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/rpn/v2/42", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf(`{"id": 42, "description": "qinq", "status": "ACTIVE", "type": "QINQ",
+			"compatibility_rpn_v1": %t,
+			"member": [{"id": 1, "linked": {"id": 7}, "status": "ACTIVE", "vlan": 5}]}`, compatibility)))
+	})
+	mux.HandleFunc("/api/v1/rpn/v2/42/", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		compatibility = r.Method == "POST"
+		w.Write([]byte(`true`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	r := &RPNv2{ID: 42, Name: "qinq", Type: QinQ, CompatibilityRPNv1: true, Members: []*Member{{}}}
+	r.Members[0].Linked.ID = 7
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	assert.NoError(t, c.SetRPNv2(r, time.Minute))
+	// the members of QinQ groups keep the VLAN reported by the API
+	assert.Equal(t, []string{"POST /api/v1/rpn/v2/42/compatibility"}, calls)
+	assert.True(t, compatibility)
+}
@@ -0,0 +1,170 @@
+package online
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrorKind classifies the errors returned by the API.
+type ErrorKind int
+
+const (
+	// ErrUnknown is any error not matching another kind.
+	ErrUnknown ErrorKind = iota
+	// ErrNotFound is returned when the requested object doesn't exist.
+	ErrNotFound
+	// ErrAlreadyExists is returned when creating or provisioning an object
+	// that is already there.
+	ErrAlreadyExists
+	// ErrRateLimited is returned when too many requests were sent.
+	ErrRateLimited
+	// ErrUnauthorized is returned when the token is invalid or lacks the
+	// permissions for the request.
+	ErrUnauthorized
+	// ErrConflict is returned when another operation is in progress on the
+	// same object.
+	ErrConflict
+	// ErrValidation is returned when the request parameters are invalid.
+	ErrValidation
+)
+
+// rpnv2NotFoundCode is the code of the RPNv2 not found error, only meaningful
+// in answers of the /rpn/v2 endpoints
+const rpnv2NotFoundCode = 7
+
+// ErrorResponse is an error answered by the API.
+type ErrorResponse struct {
+	// StatusCode is the HTTP status of the answer.
+	StatusCode int
+	// Code is the API error code, if any.
+	Code    int
+	Message string `json:"error"`
+	Kind    ErrorKind
+}
+
+func decodeErrorResponse(status int, path string, b []byte) error {
+	e := &ErrorResponse{StatusCode: status}
+
+	values := map[string]interface{}{}
+	err := json.Unmarshal(b, &values)
+	if err != nil {
+		goto Unexpected
+	}
+
+	if msg, ok := values["error"]; ok {
+		if e.Message, ok = msg.(string); !ok {
+			goto Unexpected
+		}
+	}
+
+	if msg, ok := values["error_description"]; ok {
+		if e.Message, ok = msg.(string); !ok {
+			goto Unexpected
+		}
+	}
+
+	if code, ok := values["code"]; ok {
+		code, ok := code.(float64)
+		if !ok {
+			goto Unexpected
+		}
+
+		e.Code = int(code)
+	}
+
+	e.Kind = classifyError(e, path)
+	return e
+
+Unexpected:
+	e.Code = 0
+	e.Message = fmt.Sprintf("unexpected answer from server: %s", b)
+	e.Kind = classifyError(e, path)
+	return e
+}
+
+// classifyError returns the kind of an error answered for the given path,
+// from its HTTP status and API code.
+func classifyError(e *ErrorResponse, path string) ErrorKind {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusConflict:
+		return ErrConflict
+	}
+
+	if e.Code == rpnv2NotFoundCode && strings.Contains(path, "/rpn/v2") {
+		return ErrNotFound
+	}
+
+	// some endpoints answer a generic 400 for objects already there or busy,
+	// only their message tells them apart from invalid parameters
+	msg := strings.ToLower(e.Message)
+	switch {
+	case strings.Contains(msg, "already provisioned"), strings.Contains(msg, "already exists"):
+		return ErrAlreadyExists
+	case strings.Contains(msg, "in progress"):
+		return ErrConflict
+	}
+
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrValidation
+	}
+
+	return ErrUnknown
+}
+
+func (e *ErrorResponse) Error() string {
+	if e.Code == 0 && e.StatusCode != 0 {
+		return fmt.Sprintf("%s (status: %d)", e.Message, e.StatusCode)
+	}
+
+	return fmt.Sprintf("%s (code: %d)", e.Message, e.Code)
+}
+
+func errorKind(err error) ErrorKind {
+	if e, ok := err.(*ErrorResponse); ok {
+		return e.Kind
+	}
+
+	return ErrUnknown
+}
+
+// IsNotFound returns true if err is an API error for a missing object.
+func IsNotFound(err error) bool {
+	return errorKind(err) == ErrNotFound
+}
+
+// IsAlreadyExists returns true if err is an API error for an object already
+// created or provisioned.
+func IsAlreadyExists(err error) bool {
+	return errorKind(err) == ErrAlreadyExists
+}
+
+// IsRateLimited returns true if err is an API error for too many requests.
+func IsRateLimited(err error) bool {
+	return errorKind(err) == ErrRateLimited
+}
+
+// IsUnauthorized returns true if err is an API error for an invalid token or
+// missing permissions.
+func IsUnauthorized(err error) bool {
+	return errorKind(err) == ErrUnauthorized
+}
+
+// IsConflict returns true if err is an API error for an operation already in
+// progress.
+func IsConflict(err error) bool {
+	return errorKind(err) == ErrConflict
+}
+
+// IsValidation returns true if err is an API error for invalid parameters.
+func IsValidation(err error) bool {
+	return errorKind(err) == ErrValidation
+}
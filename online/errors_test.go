@@ -0,0 +1,84 @@
+package online
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeErrorResponse(t *testing.T) {
+	cases := []struct {
+		status int
+		path   string
+		body   string
+		kind   ErrorKind
+		msg    string
+	}{
+		{http.StatusNotFound, "/api/v1/server/1", `{"error": "Server not found", "code": 3}`, ErrNotFound,
+			"Server not found (code: 3)"},
+		{http.StatusBadRequest, "/api/v1/rpn/v2/1", `{"error": "RPN not found", "code": 7}`, ErrNotFound,
+			"RPN not found (code: 7)"},
+		{http.StatusBadRequest, "/api/v1/server/1", `{"error": "invalid os", "code": 7}`, ErrValidation, ""},
+		{http.StatusNotFound, "/api/v1/server/ip/edit", `{"error": "Address already exists", "code": 5}`,
+			ErrNotFound, ""},
+		{http.StatusConflict, "/api/v1/server/ip/edit", `{"error": "conflict"}`, ErrConflict,
+			"conflict (status: 409)"},
+		{http.StatusTooManyRequests, "/api/v1/server", `{"error": "slow down"}`, ErrRateLimited, ""},
+		{http.StatusUnauthorized, "/api/v1/server", `{"error": "invalid_token", "error_description": "bad token"}`,
+			ErrUnauthorized, ""},
+		{http.StatusForbidden, "/api/v1/server", `{"error": "forbidden"}`, ErrUnauthorized, ""},
+		{http.StatusBadRequest, "/api/v1/server/1", `{"error": "invalid hostname", "code": 2}`, ErrValidation, ""},
+		{http.StatusBadGateway, "/api/v1/server", `<html>bad gateway</html>`, ErrUnknown,
+			"unexpected answer from server: <html>bad gateway</html> (status: 502)"},
+	}
+
+	for _, c := range cases {
+		err := decodeErrorResponse(c.status, c.path, []byte(c.body))
+		e, ok := err.(*ErrorResponse)
+		if !assert.True(t, ok, c.body) {
+			continue
+		}
+
+		assert.Equal(t, c.status, e.StatusCode, c.body)
+		assert.Equal(t, c.kind, e.Kind, c.body)
+		if c.msg != "" {
+			assert.EqualError(t, err, c.msg)
+		}
+	}
+}
+
+func TestDecodeErrorResponseMessageFallback(t *testing.T) {
+	// generic 400 answers are told apart by their message only
+	cases := []struct {
+		body string
+		kind ErrorKind
+	}{
+		{`{"error": "Address already provisioned", "code": 5}`, ErrAlreadyExists},
+		{`{"error": "Group already exists", "code": 5}`, ErrAlreadyExists},
+		{`{"error": "Operation in progress", "code": 9}`, ErrConflict},
+		{`{"error": "Operation failed", "code": 9}`, ErrValidation},
+	}
+
+	for _, c := range cases {
+		err := decodeErrorResponse(http.StatusBadRequest, "/api/v1/server/failover/edit", []byte(c.body))
+		e, ok := err.(*ErrorResponse)
+		if assert.True(t, ok, c.body) {
+			assert.Equal(t, c.kind, e.Kind, c.body)
+		}
+	}
+}
+
+func TestErrorHelpers(t *testing.T) {
+	assert.True(t, IsNotFound(&ErrorResponse{Kind: ErrNotFound}))
+	assert.True(t, IsAlreadyExists(&ErrorResponse{Kind: ErrAlreadyExists}))
+	assert.True(t, IsRateLimited(&ErrorResponse{Kind: ErrRateLimited}))
+	assert.True(t, IsUnauthorized(&ErrorResponse{Kind: ErrUnauthorized}))
+	assert.True(t, IsConflict(&ErrorResponse{Kind: ErrConflict}))
+	assert.True(t, IsValidation(&ErrorResponse{Kind: ErrValidation}))
+
+	assert.False(t, IsNotFound(&ErrorResponse{Kind: ErrConflict}))
+	assert.False(t, IsNotFound(errors.New("not found")))
+	assert.False(t, IsNotFound(nil))
+}
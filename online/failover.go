@@ -1,11 +1,15 @@
 package online
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 )
 
 func (c *client) EditFailoverIP(source, destination string) error {
-	target := fmt.Sprintf("%s/failover/edit", serverEndPoint)
+	target := fmt.Sprintf("%s/failover/edit", c.serverEndPoint)
 	_, err := c.doPOST(target, map[string]string{
 		"source":      source,
 		"destination": destination,
@@ -18,7 +22,7 @@ func (c *client) EditFailoverIP(source, destination string) error {
 }
 
 func (c *client) GenerateMACFailoverIP(address, macType string) (string, error) {
-	target := fmt.Sprintf("%s/failover/generateMac", serverEndPoint)
+	target := fmt.Sprintf("%s/failover/generateMac", c.serverEndPoint)
 	body, err := c.doPOST(target, map[string]string{
 		"address": address,
 		"type":    macType,
@@ -31,7 +35,7 @@ func (c *client) GenerateMACFailoverIP(address, macType string) (string, error)
 }
 
 func (c *client) DeleteMACFailoverIP(address string) error {
-	target := fmt.Sprintf("%s/failover/deleteMac", serverEndPoint)
+	target := fmt.Sprintf("%s/failover/deleteMac", c.serverEndPoint)
 	_, err := c.doPOST(target, map[string]string{
 		"address": address,
 	})
@@ -41,3 +45,171 @@ func (c *client) DeleteMACFailoverIP(address string) error {
 
 	return nil
 }
+
+// SetFailoverIPReverse sets the reverse DNS of a failover IP.
+func (c *client) SetFailoverIPReverse(address, reverse string) error {
+	return c.doSetServerIP(&Interface{Address: address, Reverse: reverse})
+}
+
+// FailoverIP is a failover IP of the account
+type FailoverIP struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	MAC         string `json:"mac"`
+	Reverse     string `json:"reverse"`
+	Type        string `json:"type"`
+}
+
+// ListFailoverIPs returns every failover IP of the account, with the address
+// of the server it is routed to, if any.
+func (c *client) ListFailoverIPs() ([]*FailoverIP, error) {
+	target := fmt.Sprintf("%s/failover", c.serverEndPoint)
+	body, err := c.doGET(target)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*FailoverIP
+	return list, json.Unmarshal(body, &list)
+}
+
+// failoverPollInterval is the interval between two checks of the failover IPs
+// routing, when no pacing is given
+const failoverPollInterval = 5 * time.Second
+
+// MoveFailoverIPs routes every failover IP to the destination address, an
+// empty destination unroutes them. The IPs are moved one at a time, pacing
+// apart, and a move rejected because a change is in progress is retried
+// every pacing. The final routing is then verified, waiting at most wait,
+// and if a move or the verification fails the IPs already moved are routed
+// back to their previous destination.
+func (c *client) MoveFailoverIPs(ips []string, destination string, pacing, wait time.Duration) error {
+	list, err := c.ListFailoverIPs()
+	if err != nil {
+		return err
+	}
+
+	previous := make(map[string]string)
+	for _, ip := range ips {
+		f := FindFailoverIP(list, ip)
+		if f == nil {
+			return fmt.Errorf("failover IP %s is not part of the account", ip)
+		}
+
+		previous[ip] = f.Destination
+	}
+
+	until := time.Now().Add(wait)
+
+	retry := failoverRetryInterval(pacing)
+
+	var moved []string
+	for _, ip := range ips {
+		if previous[ip] == destination {
+			continue
+		}
+
+		if len(moved) != 0 {
+			if err := c.sleep(pacing); err != nil {
+				return err
+			}
+		}
+
+		log.Printf("[DEBUG] moving failover IP %s from %q to %q", ip, previous[ip], destination)
+		if err := c.editFailoverIPPaced(ip, destination, retry, time.Until(until)); err != nil {
+			err = fmt.Errorf("moving failover IP %s failed: %s", ip, err)
+			return c.rollbackFailoverIPs(moved, previous, pacing, wait, err)
+		}
+
+		moved = append(moved, ip)
+	}
+
+	description := fmt.Sprintf("failover IPs to be routed to %q", destination)
+	cond := FailoverIPsRouted(c, ips, destination)
+	if err := c.waitFor(description, retry, time.Until(until), cond); err != nil {
+		return c.rollbackFailoverIPs(moved, previous, pacing, wait, err)
+	}
+
+	return nil
+}
+
+// failoverRetryInterval returns the interval between two retries or checks
+// of failover IPs changes for the given pacing.
+func failoverRetryInterval(pacing time.Duration) time.Duration {
+	if pacing <= 0 {
+		return failoverPollInterval
+	}
+
+	return pacing
+}
+
+// editFailoverIPPaced edits the failover IP, retrying every interval while the
+// API reports a change in progress.
+func (c *client) editFailoverIPPaced(ip, destination string, interval, wait time.Duration) error {
+	edit := func() (bool, error) {
+		err := c.EditFailoverIP(ip, destination)
+		switch {
+		case IsConflict(err):
+			log.Printf("[DEBUG] failover IP %s has a change in progress, retrying in %s", ip, interval)
+			return false, nil
+		case IsAlreadyExists(err):
+			return true, nil
+		}
+
+		return err == nil, err
+	}
+
+	if done, err := edit(); done || err != nil {
+		return err
+	}
+
+	w := &Waiter{
+		Description: fmt.Sprintf("failover IP %s to accept changes", ip),
+		Interval:    interval,
+		Timeout:     wait,
+	}
+
+	return w.Wait(c.ctx, edit)
+}
+
+// rollbackFailoverIPs routes the moved IPs back to their previous destination,
+// in reverse order, and returns cause completed with the rollback outcome.
+func (c *client) rollbackFailoverIPs(moved []string, previous map[string]string, pacing, wait time.Duration,
+	cause error) error {
+	if len(moved) == 0 {
+		return cause
+	}
+
+	retry := failoverRetryInterval(pacing)
+
+	var failed []string
+	for i := len(moved) - 1; i >= 0; i-- {
+		ip := moved[i]
+		if err := c.sleep(pacing); err != nil {
+			return fmt.Errorf("%s, rolling back was interrupted: %s", cause, err)
+		}
+
+		log.Printf("[DEBUG] rolling back failover IP %s to %q", ip, previous[ip])
+		if err := c.editFailoverIPPaced(ip, previous[ip], retry, wait); err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%s)", ip, err))
+		}
+	}
+
+	if len(failed) != 0 {
+		return fmt.Errorf("%s, rolling back failed for %s", cause, strings.Join(failed, ", "))
+	}
+
+	return fmt.Errorf("%s, moved failover IPs were routed back", cause)
+}
+
+// FindFailoverIP returns the failover IP of the list with the given address,
+// or nil if there is none.
+func FindFailoverIP(list []*FailoverIP, ip string) *FailoverIP {
+	for _, f := range list {
+		if f.Source == ip {
+			return f
+		}
+	}
+
+	return nil
+}
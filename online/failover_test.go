@@ -0,0 +1,167 @@
+package online
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failoverAPI fakes the failover endpoints of the API
+type failoverAPI struct {
+	sync.Mutex
+	routes map[string]string
+	// conflicts is the number of edits answered with a change in progress
+	conflicts map[string]int
+	// broken are the edits failing, as source>destination
+	broken map[string]bool
+	edits  []string
+}
+
+func newFailoverAPI(routes map[string]string) (*failoverAPI, *httptest.Server) {
+	api := &failoverAPI{routes: routes, conflicts: map[string]int{}, broken: map[string]bool{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/server/failover", func(w http.ResponseWriter, r *http.Request) {
+		api.Lock()
+		defer api.Unlock()
+
+		var list []*FailoverIP
+		for source, destination := range api.routes {
+			list = append(list, &FailoverIP{Source: source, Destination: destination})
+		}
+
+		sort.Slice(list, func(i, j int) bool { return list[i].Source < list[j].Source })
+		json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("/api/v1/server/failover/edit", func(w http.ResponseWriter, r *http.Request) {
+		api.Lock()
+		defer api.Unlock()
+
+		source, destination := r.FormValue("source"), r.FormValue("destination")
+		edit := source + ">" + destination
+		api.edits = append(api.edits, edit)
+
+		if api.conflicts[source] > 0 {
+			api.conflicts[source]--
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "An operation is already in progress", "code": 2}`))
+			return
+		}
+
+		if api.broken[edit] {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "Invalid destination", "code": 2}`))
+			return
+		}
+
+		api.routes[source] = destination
+		w.Write([]byte(`true`))
+	})
+
+	return api, httptest.NewServer(mux)
+}
+
+func TestMoveFailoverIPs(t *testing.T) {
+	api, srv := newFailoverAPI(map[string]string{
+		"1.1.1.1": "10.0.0.1",
+		"1.1.1.2": "10.0.0.1",
+		"1.1.1.3": "10.0.0.2",
+	})
+	defer srv.Close()
+	api.conflicts["1.1.1.2"] = 2
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	err := c.MoveFailoverIPs([]string{"1.1.1.1", "1.1.1.2", "1.1.1.3"}, "10.0.0.2", time.Millisecond, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"1.1.1.1": "10.0.0.2",
+		"1.1.1.2": "10.0.0.2",
+		"1.1.1.3": "10.0.0.2",
+	}, api.routes)
+	assert.Equal(t, []string{
+		"1.1.1.1>10.0.0.2",
+		"1.1.1.2>10.0.0.2",
+		"1.1.1.2>10.0.0.2",
+		"1.1.1.2>10.0.0.2",
+	}, api.edits)
+}
+
+func TestMoveFailoverIPsRollback(t *testing.T) {
+	api, srv := newFailoverAPI(map[string]string{
+		"1.1.1.1": "10.0.0.1",
+		"1.1.1.2": "",
+		"1.1.1.3": "10.0.0.1",
+	})
+	defer srv.Close()
+	api.broken["1.1.1.3>10.0.0.2"] = true
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	err := c.MoveFailoverIPs([]string{"1.1.1.1", "1.1.1.2", "1.1.1.3"}, "10.0.0.2", time.Millisecond, time.Second)
+	assert.EqualError(t, err, "moving failover IP 1.1.1.3 failed: Invalid destination (code: 2), "+
+		"moved failover IPs were routed back")
+	assert.Equal(t, map[string]string{
+		"1.1.1.1": "10.0.0.1",
+		"1.1.1.2": "",
+		"1.1.1.3": "10.0.0.1",
+	}, api.routes)
+	assert.Equal(t, []string{
+		"1.1.1.1>10.0.0.2",
+		"1.1.1.2>10.0.0.2",
+		"1.1.1.3>10.0.0.2",
+		"1.1.1.2>",
+		"1.1.1.1>10.0.0.1",
+	}, api.edits)
+}
+
+func TestMoveFailoverIPsRollbackFailure(t *testing.T) {
+	api, srv := newFailoverAPI(map[string]string{
+		"1.1.1.1": "10.0.0.1",
+		"1.1.1.2": "10.0.0.1",
+	})
+	defer srv.Close()
+	api.broken["1.1.1.2>10.0.0.2"] = true
+	api.broken["1.1.1.1>10.0.0.1"] = true
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	err := c.MoveFailoverIPs([]string{"1.1.1.1", "1.1.1.2"}, "10.0.0.2", time.Millisecond, time.Second)
+	assert.EqualError(t, err, "moving failover IP 1.1.1.2 failed: Invalid destination (code: 2), "+
+		"rolling back failed for 1.1.1.1 (Invalid destination (code: 2))")
+	assert.Equal(t, "10.0.0.2", api.routes["1.1.1.1"])
+}
+
+func TestMoveFailoverIPsUnknown(t *testing.T) {
+	api, srv := newFailoverAPI(map[string]string{"1.1.1.1": ""})
+	defer srv.Close()
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	err := c.MoveFailoverIPs([]string{"1.1.1.1", "1.1.1.9"}, "10.0.0.2", time.Millisecond, time.Second)
+	assert.EqualError(t, err, "failover IP 1.1.1.9 is not part of the account")
+	assert.Empty(t, api.edits)
+}
+
+func TestFailoverIPsRoutedCondition(t *testing.T) {
+	_, srv := newFailoverAPI(map[string]string{"1.1.1.1": "10.0.0.1", "1.1.1.2": ""})
+	defer srv.Close()
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	for _, tc := range []struct {
+		ips         []string
+		destination string
+		done        bool
+	}{
+		{[]string{"1.1.1.1"}, "10.0.0.1", true},
+		{[]string{"1.1.1.1", "1.1.1.2"}, "10.0.0.1", false},
+		{[]string{"1.1.1.2"}, "", true},
+	} {
+		done, err := FailoverIPsRouted(c, tc.ips, tc.destination)()
+		assert.NoError(t, err)
+		assert.Equal(t, tc.done, done, fmt.Sprintf("%v to %q", tc.ips, tc.destination))
+	}
+}
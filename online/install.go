@@ -0,0 +1,124 @@
+package online
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// installPollInterval is the interval between two checks of an install status
+const installPollInterval = 10 * time.Second
+
+// InstallStatus values reported while installing a server
+const (
+	InstallStatusInstalling = "installing"
+	InstallStatusInstalled  = "installed"
+	InstallStatusFailed     = "failed"
+)
+
+// OperatingSystem is an operating system installable on a server
+type OperatingSystem struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Type      string `json:"type"`
+	Arch      string `json:"arch"`
+	Release   string `json:"release"`
+	EndOfLife string `json:"end_of_life"`
+}
+
+// Install contains the parameters of an operating system installation
+type Install struct {
+	OSID          int
+	Hostname      string
+	UserLogin     string
+	UserPassword  string
+	RootPassword  string
+	PanelPassword string
+	SSHKeys       []string
+	// PartitioningTemplate is the reference of the partitioning template to
+	// use, the offer default layout is used if empty and no Partitions are
+	// given
+	PartitioningTemplate string
+	Partitions           []*Partition
+}
+
+func (c *client) OperatingSystems(serverID int) ([]*OperatingSystem, error) {
+	target := fmt.Sprintf("%s/operatingSystems/%d", c.serverEndPoint, serverID)
+	body, err := c.doGET(target)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*OperatingSystem
+	return list, json.Unmarshal(body, &list)
+}
+
+// InstallServer installs an operating system on the server, erasing all its
+// data, and waits for the installation to finish.
+func (c *client) InstallServer(serverID int, i *Install, wait time.Duration) error {
+	values := map[string]string{
+		"os_id":    strconv.Itoa(i.OSID),
+		"hostname": i.Hostname,
+	}
+
+	optional := map[string]string{
+		"user_login":                i.UserLogin,
+		"user_password":             i.UserPassword,
+		"root_password":             i.RootPassword,
+		"panel_password":            i.PanelPassword,
+		"partitioning_template_ref": i.PartitioningTemplate,
+	}
+
+	for k, v := range optional {
+		if v != "" {
+			values[k] = v
+		}
+	}
+
+	if len(i.Partitions) != 0 {
+		partitionsJSON, err := json.Marshal(i.Partitions)
+		if err != nil {
+			return err
+		}
+
+		values["partitions"] = string(partitionsJSON)
+	}
+
+	if len(i.SSHKeys) != 0 {
+		keysJSON, _ := json.Marshal(i.SSHKeys)
+		values["ssh_keys"] = string(keysJSON)
+	}
+
+	target := fmt.Sprintf("%s/install/%d", c.serverEndPoint, serverID)
+	if _, err := c.doPOST(target, values); err != nil {
+		return err
+	}
+
+	return c.waitInstall(serverID, wait)
+}
+
+// InstallStatus returns the status of the last installation of the server
+func (c *client) InstallStatus(serverID int) (string, error) {
+	target := fmt.Sprintf("%s/install/status/%d", c.serverEndPoint, serverID)
+	body, err := c.doGET(target)
+	if err != nil {
+		return "", err
+	}
+
+	status := struct {
+		Status string `json:"status"`
+	}{}
+
+	if err := json.Unmarshal(body, &status); err != nil {
+		return "", err
+	}
+
+	return status.Status, nil
+}
+
+func (c *client) waitInstall(serverID int, wait time.Duration) error {
+	description := fmt.Sprintf("server %d installation", serverID)
+	return c.waitFor(description, installPollInterval, wait, ServerInstalled(c, serverID))
+}
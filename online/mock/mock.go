@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"context"
 	"time"
 
 	"github.com/src-d/terraform-provider-online/online"
@@ -12,18 +13,76 @@ type OnlineClientMock struct {
 	mock.Mock
 }
 
+// WithContext returns the mock itself, the context is ignored
+func (o *OnlineClientMock) WithContext(ctx context.Context) online.Client {
+	return o
+}
+
+// Context returns the background context
+func (o *OnlineClientMock) Context() context.Context {
+	return context.Background()
+}
+
 // Server is a mock call
 func (o *OnlineClientMock) Server(id int) (*online.Server, error) {
 	args := o.Called(id)
 	return args.Get(0).(*online.Server), args.Error(1)
 }
 
+// ListServers is a mock call
+func (o *OnlineClientMock) ListServers() ([]*online.Server, error) {
+	args := o.Called()
+	return args.Get(0).([]*online.Server), args.Error(1)
+}
+
 // SetServer is a mock call
 func (o *OnlineClientMock) SetServer(s *online.Server) error {
 	args := o.Called(s)
 	return args.Error(0)
 }
 
+// OperatingSystems is a mock call
+func (o *OnlineClientMock) OperatingSystems(serverID int) ([]*online.OperatingSystem, error) {
+	args := o.Called(serverID)
+	return args.Get(0).([]*online.OperatingSystem), args.Error(1)
+}
+
+// InstallServer is a mock call
+func (o *OnlineClientMock) InstallServer(serverID int, i *online.Install, wait time.Duration) error {
+	args := o.Called(serverID, i, wait)
+	return args.Error(0)
+}
+
+// InstallStatus is a mock call
+func (o *OnlineClientMock) InstallStatus(serverID int) (string, error) {
+	args := o.Called(serverID)
+	return args.String(0), args.Error(1)
+}
+
+// ListPartitioningTemplates is a mock call
+func (o *OnlineClientMock) ListPartitioningTemplates() ([]*online.PartitioningTemplate, error) {
+	args := o.Called()
+	return args.Get(0).([]*online.PartitioningTemplate), args.Error(1)
+}
+
+// PartitioningTemplate is a mock call
+func (o *OnlineClientMock) PartitioningTemplate(id int) (*online.PartitioningTemplate, error) {
+	args := o.Called(id)
+	return args.Get(0).(*online.PartitioningTemplate), args.Error(1)
+}
+
+// SetPartitioningTemplate is a mock call
+func (o *OnlineClientMock) SetPartitioningTemplate(t *online.PartitioningTemplate) error {
+	args := o.Called(t)
+	return args.Error(0)
+}
+
+// DeletePartitioningTemplate is a mock call
+func (o *OnlineClientMock) DeletePartitioningTemplate(id int) error {
+	args := o.Called(id)
+	return args.Error(0)
+}
+
 // GetRescueImages is a mock call
 func (o *OnlineClientMock) GetRescueImages(serverID int) ([]string, error) {
 	args := o.Called(serverID)
@@ -60,6 +119,24 @@ func (o *OnlineClientMock) DeleteRPNv2(id int, wait time.Duration) error {
 	return args.Error(0)
 }
 
+// BootServer is a mock call
+func (o *OnlineClientMock) BootServer(serverID int, wait time.Duration) error {
+	args := o.Called(serverID, wait)
+	return args.Error(0)
+}
+
+// ShutdownServer is a mock call
+func (o *OnlineClientMock) ShutdownServer(serverID int, reason string, wait time.Duration) error {
+	args := o.Called(serverID, reason, wait)
+	return args.Error(0)
+}
+
+// RebootServer is a mock call
+func (o *OnlineClientMock) RebootServer(serverID int, reason string, wait time.Duration) error {
+	args := o.Called(serverID, reason, wait)
+	return args.Error(0)
+}
+
 // BootRescueMode is a mock call
 func (o *OnlineClientMock) BootRescueMode(serverID int, image string) (*online.RescueCredentials, error) {
 	args := o.Called(serverID, image)
@@ -72,12 +149,30 @@ func (o *OnlineClientMock) BootNormalMode(serverID int) error {
 	return args.Error(0)
 }
 
+// WaitBootMode is a mock call
+func (o *OnlineClientMock) WaitBootMode(serverID int, mode string, wait time.Duration) error {
+	args := o.Called(serverID, mode, wait)
+	return args.Error(0)
+}
+
+// ListFailoverIPs is a mock call
+func (o *OnlineClientMock) ListFailoverIPs() ([]*online.FailoverIP, error) {
+	args := o.Called()
+	return args.Get(0).([]*online.FailoverIP), args.Error(1)
+}
+
 // EditFailoverIP is a mock call
 func (o *OnlineClientMock) EditFailoverIP(source, destination string) error {
 	args := o.Called(source, destination)
 	return args.Error(0)
 }
 
+// MoveFailoverIPs is a mock call
+func (o *OnlineClientMock) MoveFailoverIPs(ips []string, destination string, pacing, wait time.Duration) error {
+	args := o.Called(ips, destination, pacing, wait)
+	return args.Error(0)
+}
+
 // GenerateMACFailoverIP is a mock call
 func (o *OnlineClientMock) GenerateMACFailoverIP(address, macType string) (string, error) {
 	args := o.Called(address, macType)
@@ -89,3 +184,9 @@ func (o *OnlineClientMock) DeleteMACFailoverIP(address string) error {
 	args := o.Called(address)
 	return args.Error(0)
 }
+
+// SetFailoverIPReverse is a mock call
+func (o *OnlineClientMock) SetFailoverIPReverse(address, reverse string) error {
+	args := o.Called(address, reverse)
+	return args.Error(0)
+}
@@ -0,0 +1,217 @@
+package online
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// FileSystems are the file systems supported in a partition, lvm partitions
+// are LVM physical volumes
+var FileSystems = []string{"ext3", "ext4", "xfs", "swap", "lvm"}
+
+// RaidLevels are the RAID levels supported in a partition, an empty level
+// means no RAID
+var RaidLevels = []string{"RAID0", "RAID1", "RAID5", "RAID6", "RAID10"}
+
+// raidMinDisks is the minimum number of disks required by each RAID level
+var raidMinDisks = map[string]int{
+	"":       1,
+	"RAID0":  2,
+	"RAID1":  2,
+	"RAID5":  3,
+	"RAID6":  4,
+	"RAID10": 4,
+}
+
+// Partition is a partition created when installing a server
+type Partition struct {
+	// DriveArray is the index of the drive array, in Server.DriveArrays,
+	// holding the partition
+	DriveArray int    `json:"drive_array"`
+	RaidLevel  string `json:"raid_level,omitempty"`
+	MountPoint string `json:"mount_point,omitempty"`
+	FileSystem string `json:"file_system"`
+	// Size of the partition in MB, zero uses the space left on the array
+	Size int `json:"size"`
+}
+
+// PartitioningTemplate is a reusable set of partitions
+type PartitioningTemplate struct {
+	ID         int          `json:"id,omitempty"`
+	Name       string       `json:"name"`
+	Partitions []*Partition `json:"partitions"`
+}
+
+func (c *client) partitioningEndPoint() string {
+	return fmt.Sprintf("%s/partitioning/templates", c.serverEndPoint)
+}
+
+func (c *client) ListPartitioningTemplates() ([]*PartitioningTemplate, error) {
+	body, err := c.doGET(c.partitioningEndPoint())
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*PartitioningTemplate
+	return list, json.Unmarshal(body, &list)
+}
+
+func (c *client) PartitioningTemplate(id int) (*PartitioningTemplate, error) {
+	target := fmt.Sprintf("%s/%d", c.partitioningEndPoint(), id)
+	body, err := c.doGET(target)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &PartitioningTemplate{}
+	return t, json.Unmarshal(body, t)
+}
+
+// SetPartitioningTemplate creates the template if its ID is zero, setting it,
+// or updates it otherwise.
+func (c *client) SetPartitioningTemplate(t *PartitioningTemplate) error {
+	if err := ValidatePartitions(t.Partitions, nil); err != nil {
+		return err
+	}
+
+	partitionsJSON, err := json.Marshal(t.Partitions)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]string{
+		"name":       t.Name,
+		"partitions": string(partitionsJSON),
+	}
+
+	if t.ID != 0 {
+		target := fmt.Sprintf("%s/%d", c.partitioningEndPoint(), t.ID)
+		_, err := c.doPUT(target, values)
+		return err
+	}
+
+	body, err := c.doPOST(c.partitioningEndPoint(), values)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, t)
+}
+
+func (c *client) DeletePartitioningTemplate(id int) error {
+	target := fmt.Sprintf("%s/%d", c.partitioningEndPoint(), id)
+	_, err := c.doDELETE(target, nil)
+	return err
+}
+
+// ValidatePartitions checks the consistency of a partition layout and, if s
+// is not nil, that it fits the server drive arrays.
+func ValidatePartitions(partitions []*Partition, s *Server) error {
+	if len(partitions) == 0 {
+		return fmt.Errorf("at least one partition is required")
+	}
+
+	mounts := map[string]bool{}
+	raidLevels := map[int]string{}
+	fillers := map[int]bool{}
+	for i, p := range partitions {
+		if err := validatePartition(p); err != nil {
+			return fmt.Errorf("partition %d: %s", i, err)
+		}
+
+		if p.MountPoint != "" {
+			if mounts[p.MountPoint] {
+				return fmt.Errorf("partition %d: mount point %s used several times", i, p.MountPoint)
+			}
+
+			mounts[p.MountPoint] = true
+		}
+
+		if level, ok := raidLevels[p.DriveArray]; ok && level != p.RaidLevel {
+			return fmt.Errorf("partition %d: drive array %d can't mix RAID levels %q and %q",
+				i, p.DriveArray, level, p.RaidLevel)
+		}
+
+		raidLevels[p.DriveArray] = p.RaidLevel
+
+		if p.Size == 0 {
+			if fillers[p.DriveArray] {
+				return fmt.Errorf("partition %d: only one partition can use the space left on drive array %d",
+					i, p.DriveArray)
+			}
+
+			fillers[p.DriveArray] = true
+		}
+	}
+
+	if !mounts["/"] {
+		return fmt.Errorf("a partition mounted on / is required")
+	}
+
+	if s == nil {
+		return nil
+	}
+
+	disks := make([]int, len(s.DriveArrays))
+	for i, a := range s.DriveArrays {
+		disks[i] = len(a.Disks)
+	}
+
+	// servers without RAID controller have no drive array, their disks are
+	// handled as a single one
+	if len(disks) == 0 && len(s.Disks) != 0 {
+		disks = []int{len(s.Disks)}
+	}
+
+	var arrays []int
+	for array := range raidLevels {
+		arrays = append(arrays, array)
+	}
+
+	sort.Ints(arrays)
+	for _, array := range arrays {
+		level := raidLevels[array]
+		if array >= len(disks) {
+			return fmt.Errorf("drive array %d doesn't exist, server %d has %d", array, s.ID, len(disks))
+		}
+
+		if disks[array] < raidMinDisks[level] {
+			return fmt.Errorf("%s requires %d disks, drive array %d of server %d has %d",
+				level, raidMinDisks[level], array, s.ID, disks[array])
+		}
+	}
+
+	return nil
+}
+
+func validatePartition(p *Partition) error {
+	if p.DriveArray < 0 {
+		return fmt.Errorf("invalid drive array %d", p.DriveArray)
+	}
+
+	if p.Size < 0 {
+		return fmt.Errorf("invalid size %d", p.Size)
+	}
+
+	if _, ok := raidMinDisks[p.RaidLevel]; !ok {
+		return fmt.Errorf("unknown RAID level %q, must be one of %s", p.RaidLevel, strings.Join(RaidLevels, ", "))
+	}
+
+	switch p.FileSystem {
+	case "swap", "lvm":
+		if p.MountPoint != "" {
+			return fmt.Errorf("%s partitions can't be mounted", p.FileSystem)
+		}
+	case "ext3", "ext4", "xfs":
+		if !path.IsAbs(p.MountPoint) || path.Clean(p.MountPoint) != p.MountPoint {
+			return fmt.Errorf("invalid mount point %q", p.MountPoint)
+		}
+	default:
+		return fmt.Errorf("unknown file system %q, must be one of %s", p.FileSystem, strings.Join(FileSystems, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package online
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePartitions(t *testing.T) {
+	s := &Server{
+		ID: 42,
+		DriveArrays: []*DriveArray{
+			{Disks: []Ref{{"/disk/1"}, {"/disk/2"}}},
+			{Disks: []Ref{{"/disk/3"}, {"/disk/4"}, {"/disk/5"}}},
+		},
+	}
+
+	root := &Partition{MountPoint: "/", FileSystem: "ext4", Size: 20000, RaidLevel: "RAID1"}
+	cases := []struct {
+		partitions []*Partition
+		err        string
+	}{
+		{[]*Partition{
+			root,
+			{FileSystem: "swap", Size: 4096, RaidLevel: "RAID1"},
+			{MountPoint: "/srv", FileSystem: "xfs", RaidLevel: "RAID1"},
+			{DriveArray: 1, FileSystem: "lvm", RaidLevel: "RAID5"},
+		}, ""},
+		{nil, "at least one partition is required"},
+		{[]*Partition{{MountPoint: "/srv", FileSystem: "ext4"}}, "a partition mounted on / is required"},
+		{[]*Partition{root, {MountPoint: "/", FileSystem: "xfs", RaidLevel: "RAID1"}},
+			"partition 1: mount point / used several times"},
+		{[]*Partition{root, {FileSystem: "swap", MountPoint: "/swap", RaidLevel: "RAID1"}},
+			"partition 1: swap partitions can't be mounted"},
+		{[]*Partition{root, {FileSystem: "ext4", MountPoint: "srv", RaidLevel: "RAID1"}},
+			`partition 1: invalid mount point "srv"`},
+		{[]*Partition{root, {FileSystem: "ntfs", MountPoint: "/srv", RaidLevel: "RAID1"}},
+			`partition 1: unknown file system "ntfs", must be one of ext3, ext4, xfs, swap, lvm`},
+		{[]*Partition{root, {FileSystem: "swap", RaidLevel: "RAID0"}},
+			`partition 1: drive array 0 can't mix RAID levels "RAID1" and "RAID0"`},
+		{[]*Partition{root, {FileSystem: "swap", RaidLevel: "RAID1"}, {FileSystem: "lvm", RaidLevel: "RAID1"}},
+			"partition 2: only one partition can use the space left on drive array 0"},
+		{[]*Partition{root, {DriveArray: 2, FileSystem: "lvm"}},
+			"drive array 2 doesn't exist, server 42 has 2"},
+		{[]*Partition{root, {DriveArray: 1, FileSystem: "lvm", RaidLevel: "RAID6"}},
+			"RAID6 requires 4 disks, drive array 1 of server 42 has 3"},
+	}
+
+	for _, c := range cases {
+		err := ValidatePartitions(c.partitions, s)
+		if c.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, c.err)
+		}
+	}
+}
+
+func TestValidatePartitionsWithoutDriveArrays(t *testing.T) {
+	s := &Server{ID: 42, Disks: []Ref{{"/disk/1"}}}
+
+	err := ValidatePartitions([]*Partition{{MountPoint: "/", FileSystem: "ext4"}}, s)
+	assert.NoError(t, err)
+
+	err = ValidatePartitions([]*Partition{{MountPoint: "/", FileSystem: "ext4", RaidLevel: "RAID1"}}, s)
+	assert.EqualError(t, err, "RAID1 requires 2 disks, drive array 0 of server 42 has 1")
+
+	err = ValidatePartitions([]*Partition{{MountPoint: "/", FileSystem: "ext4", RaidLevel: "RAID1"}}, nil)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,52 @@
+package online
+
+import (
+	"fmt"
+	"time"
+)
+
+// powerPollInterval is the interval between two checks of a server power state
+const powerPollInterval = 5 * time.Second
+
+// Power states of a server
+const (
+	PowerOn  = "ON"
+	PowerOff = "OFF"
+)
+
+// BootServer powers the server on and waits for it to be ON.
+func (c *client) BootServer(serverID int, wait time.Duration) error {
+	target := fmt.Sprintf("%s/boot/%d", c.serverEndPoint, serverID)
+	if _, err := c.doPOST(target, map[string]string{}); err != nil {
+		return err
+	}
+
+	return c.waitPower(serverID, PowerOn, wait)
+}
+
+// ShutdownServer powers the server off, with the given reason, and waits for
+// it to be OFF.
+func (c *client) ShutdownServer(serverID int, reason string, wait time.Duration) error {
+	target := fmt.Sprintf("%s/shutdown/%d", c.serverEndPoint, serverID)
+	if _, err := c.doPOST(target, map[string]string{"reason": reason}); err != nil {
+		return err
+	}
+
+	return c.waitPower(serverID, PowerOff, wait)
+}
+
+// RebootServer reboots the server, with the given reason, and waits for it
+// to be ON.
+func (c *client) RebootServer(serverID int, reason string, wait time.Duration) error {
+	target := fmt.Sprintf("%s/reboot/%d", c.serverEndPoint, serverID)
+	if _, err := c.doPOST(target, map[string]string{"reason": reason}); err != nil {
+		return err
+	}
+
+	return c.waitPower(serverID, PowerOn, wait)
+}
+
+func (c *client) waitPower(serverID int, state string, wait time.Duration) error {
+	description := fmt.Sprintf("server %d to be %s", serverID, state)
+	return c.waitFor(description, powerPollInterval, wait, ServerPower(c, serverID, state))
+}
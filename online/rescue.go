@@ -3,6 +3,17 @@ package online
 import (
 	"encoding/json"
 	"fmt"
+	"time"
+)
+
+// bootModePollInterval is the interval between two checks of a server boot
+// mode
+const bootModePollInterval = 5 * time.Second
+
+// Boot modes of a server
+const (
+	BootModeNormal = "normal"
+	BootModeRescue = "rescue"
 )
 
 // RescueCredentials contain the login details for a server that booted into rescue mode
@@ -14,7 +25,7 @@ type RescueCredentials struct {
 }
 
 func (c *client) BootRescueMode(serverID int, image string) (*RescueCredentials, error) {
-	target := fmt.Sprintf("%s/boot/rescue/%d", serverEndPoint, serverID)
+	target := fmt.Sprintf("%s/boot/rescue/%d", c.serverEndPoint, serverID)
 	body, err := c.doPOST(target, map[string]string{
 		"image": image,
 	})
@@ -32,7 +43,7 @@ func (c *client) BootRescueMode(serverID int, image string) (*RescueCredentials,
 }
 
 func (c *client) BootNormalMode(serverID int) error {
-	target := fmt.Sprintf("%s/boot/normal/%d", serverEndPoint, serverID)
+	target := fmt.Sprintf("%s/boot/normal/%d", c.serverEndPoint, serverID)
 	_, err := c.doPOST(target, map[string]string{})
 	if err != nil {
 		return err
@@ -40,3 +51,9 @@ func (c *client) BootNormalMode(serverID int) error {
 
 	return nil
 }
+
+// WaitBootMode waits for the server to report the given boot mode.
+func (c *client) WaitBootMode(serverID int, mode string, wait time.Duration) error {
+	description := fmt.Sprintf("server %d to boot in %s mode", serverID, mode)
+	return c.waitFor(description, bootModePollInterval, wait, ServerBootMode(c, serverID, mode))
+}
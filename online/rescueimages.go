@@ -6,7 +6,7 @@ import (
 )
 
 func (c *client) GetRescueImages(serverID int) ([]string, error) {
-	target := fmt.Sprintf("%s/rescue_images/%d", serverEndPoint, serverID)
+	target := fmt.Sprintf("%s/rescue_images/%d", c.serverEndPoint, serverID)
 	body, err := c.doGET(target)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,140 @@
+package online
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests failing with a transient error are
+// retried. Network errors and 5xx answers are only retried for idempotent
+// methods. 429 (Too Many Requests) answers are retried for any method when
+// the API tells when to come back with a Retry-After header, otherwise only
+// for idempotent methods.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent, including
+	// the first one. A value of 1 or less disables retries.
+	MaxAttempts int
+	// MaxElapsedTime is the maximum time spent retrying a request, zero means
+	// no limit other than MaxAttempts.
+	MaxElapsedTime time.Duration
+	// InitialInterval is the wait before the first retry, doubled after each
+	// attempt.
+	InitialInterval time.Duration
+	// MaxInterval caps the wait between two attempts, zero means no cap.
+	MaxInterval time.Duration
+
+	// sleep replaces the wait between two attempts when set, letting tests
+	// check the delays without waiting for them.
+	sleep func(time.Duration)
+}
+
+// DefaultRetryPolicy is the policy used when none is given to NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:     5,
+	MaxElapsedTime:  2 * time.Minute,
+	InitialInterval: time.Second,
+	MaxInterval:     30 * time.Second,
+}
+
+// next returns how long to wait before sending the request again, and false
+// if the request must not be retried.
+func (p *RetryPolicy) next(attempt int, elapsed time.Duration, method string, res *http.Response,
+	err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	if !isRetryable(method, res, err) {
+		return 0, false
+	}
+
+	delay, ok := time.Duration(0), false
+	if res != nil {
+		delay, ok = parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+	}
+
+	if !ok {
+		if !isIdempotent(method) {
+			return 0, false
+		}
+
+		delay = p.backoff(attempt)
+	}
+
+	if p.MaxElapsedTime > 0 && elapsed+delay > p.MaxElapsedTime {
+		return 0, false
+	}
+
+	return delay, true
+}
+
+// backoff returns the exponential wait for the given attempt, with a random
+// jitter of up to half of it.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialInterval
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxInterval > 0 && d >= p.MaxInterval {
+			d = p.MaxInterval
+			break
+		}
+	}
+
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func isRetryable(method string, res *http.Response, err error) bool {
+	if err != nil {
+		return isIdempotent(method)
+	}
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return res.StatusCode >= 500 && isIdempotent(method)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "PUT", "PATCH", "DELETE", "OPTIONS":
+		return true
+	}
+
+	return false
+}
+
+// parseRetryAfter decodes a Retry-After header, given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(header)
+	if err != nil {
+		return 0, false
+	}
+
+	delay := date.Sub(now)
+	if delay < 0 {
+		delay = 0
+	}
+
+	return delay, true
+}
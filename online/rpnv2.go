@@ -8,6 +8,10 @@ const (
 	Demo     RPNv2Type = "DEMO"
 )
 
+// RPNv2Types are the types of RPNv2 group, the members of QinQ groups have no
+// VLAN
+var RPNv2Types = []string{string(Standard), string(QinQ), string(Demo)}
+
 type RPNv2 struct {
 	ID                 int       `json:"id,omitempty"`
 	Name               string    `json:"description"`
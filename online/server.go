@@ -1,49 +1,49 @@
 package online
 
 type Server struct {
-	ID                  int         `json:"id"`
-	Offer               string      `json:"offer"`
-	Hostname            string      `json:"hostname"`
-	OS                  interface{} `json:"os"`
-	Power               string      `json:"power"`
-	BootMode            string      `json:"boot_mode"`
-	LastReboot          string      `json:"last_reboot"`
-	AntiDDOS            bool        `json:"anti_ddos"`
-	HardwareWatch       bool        `json:"hardware_watch"`
-	ProactiveMonitoring bool        `json:"proactive_monitoring"`
-	Support             string      `json:"support"`
-	Abuse               string      `json:"abuse"`
-	Location            *Location   `json:"location"`
+	ID                  int       `json:"id"`
+	Offer               string    `json:"offer"`
+	Hostname            string    `json:"hostname"`
+	OS                  *OS       `json:"os"`
+	Power               string    `json:"power"`
+	BootMode            string    `json:"boot_mode"`
+	LastReboot          string    `json:"last_reboot"`
+	AntiDDOS            bool      `json:"anti_ddos"`
+	HardwareWatch       bool      `json:"hardware_watch"`
+	ProactiveMonitoring bool      `json:"proactive_monitoring"`
+	Support             string    `json:"support"`
+	Abuse               string    `json:"abuse"`
+	Location            *Location `json:"location"`
 	Network             struct {
-		Public  []string      `json:"ip"`
-		Private []string      `json:"private"`
-		Ipfo    []interface{} `json:"ipfo"`
+		Public  []string `json:"ip"`
+		Private []string `json:"private"`
+		Ipfo    []string `json:"ipfo"`
 	} `json:"network"`
 	IP       []*Interface `json:"ip"`
 	Contacts struct {
 		Owner string `json:"owner"`
 		Tech  string `json:"tech"`
 	} `json:"contacts"`
-	Disks []struct {
-		Ref string `json:"$ref"`
-	} `json:"disks"`
-	DriveArrays []struct {
-		Disks []struct {
-			Ref string `json:"$ref"`
-		} `json:"disks"`
-		RaidController struct {
-			Ref string `json:"$ref"`
-		} `json:"raid_controller"`
-		RaidLevel string `json:"raid_level"`
-	} `json:"drive_arrays"`
-	RaidControllers []struct {
-		Ref string `json:"$ref"`
-	} `json:"raid_controllers"`
-	BMC struct {
+	Disks           []Ref         `json:"disks"`
+	DriveArrays     []*DriveArray `json:"drive_arrays"`
+	RaidControllers []Ref         `json:"raid_controllers"`
+	BMC             struct {
 		SessionKey interface{} `json:"session_key"`
 	} `json:"bmc"`
 }
 
+// Ref is a link to another API object, eg: /api/v1/server/hardware/disk/1
+type Ref struct {
+	Ref string `json:"$ref"`
+}
+
+// DriveArray is a set of disks managed by a RAID controller
+type DriveArray struct {
+	Disks          []Ref  `json:"disks"`
+	RaidController Ref    `json:"raid_controller"`
+	RaidLevel      string `json:"raid_level"`
+}
+
 type OS struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
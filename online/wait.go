@@ -0,0 +1,176 @@
+package online
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Condition reports whether a waited state is reached, an error aborts the
+// wait.
+type Condition func() (bool, error)
+
+// Waiter polls a Condition until it is met, the timeout expires or the
+// context is cancelled.
+type Waiter struct {
+	// Description of what is waited for, used in logs and errors, eg:
+	// "server 42 to be ON"
+	Description string
+	// Interval is the wait before each check of the condition.
+	Interval time.Duration
+	// Backoff multiplies the interval after each check, values below 1 keep
+	// it constant.
+	Backoff float64
+	// MaxInterval caps the interval grown by Backoff, zero means no cap.
+	MaxInterval time.Duration
+	// Timeout is the time after which the wait fails if the condition is
+	// still not met.
+	Timeout time.Duration
+}
+
+// Wait checks the condition every interval until it is met.
+func (w *Waiter) Wait(ctx context.Context, cond Condition) error {
+	start := time.Now()
+	interval := w.Interval
+
+	t := time.NewTimer(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		done, err := cond()
+		if err != nil {
+			return err
+		}
+
+		elapsed := time.Since(start)
+		if done {
+			log.Printf("[DEBUG] done waiting for %s after %s", w.Description, elapsed)
+			return nil
+		}
+
+		if elapsed >= w.Timeout {
+			return fmt.Errorf("timeout waiting for %s", w.Description)
+		}
+
+		log.Printf("[DEBUG] still waiting for %s, %s elapsed", w.Description, elapsed)
+
+		interval = w.next(interval)
+		t.Reset(interval)
+	}
+}
+
+func (w *Waiter) next(interval time.Duration) time.Duration {
+	if w.Backoff > 1 {
+		interval = time.Duration(float64(interval) * w.Backoff)
+	}
+
+	if w.MaxInterval > 0 && interval > w.MaxInterval {
+		interval = w.MaxInterval
+	}
+
+	return interval
+}
+
+// ServerBootMode is met when the server reports the given boot mode.
+func ServerBootMode(c Client, serverID int, mode string) Condition {
+	return func() (bool, error) {
+		s, err := c.Server(serverID)
+		if err != nil {
+			return false, err
+		}
+
+		return s.BootMode == mode, nil
+	}
+}
+
+// ServerPower is met when the server reports the given power state.
+func ServerPower(c Client, serverID int, state string) Condition {
+	return func() (bool, error) {
+		s, err := c.Server(serverID)
+		if err != nil {
+			return false, err
+		}
+
+		return s.Power == state, nil
+	}
+}
+
+// ServerInstalled is met when the last installation of the server finished,
+// it fails if the installation failed.
+func ServerInstalled(c Client, serverID int) Condition {
+	return func() (bool, error) {
+		status, err := c.InstallStatus(serverID)
+		if err != nil {
+			return false, err
+		}
+
+		if status == InstallStatusFailed {
+			return false, fmt.Errorf("installation of server %d failed", serverID)
+		}
+
+		return status == InstallStatusInstalled, nil
+	}
+}
+
+// RPNv2Active is met when the RPNv2 group and all its members are ACTIVE.
+func RPNv2Active(c Client, id int) Condition {
+	return func() (bool, error) {
+		rpn, err := c.RPNv2(id)
+		if err != nil {
+			return false, err
+		}
+
+		for _, m := range rpn.Members {
+			if m.Status != "ACTIVE" {
+				return false, nil
+			}
+		}
+
+		return rpn.Status == "ACTIVE", nil
+	}
+}
+
+// FailoverIPsRouted is met when every failover IP is routed to the
+// destination address, an empty destination meaning unrouted.
+func FailoverIPsRouted(c Client, ips []string, destination string) Condition {
+	return func() (bool, error) {
+		list, err := c.ListFailoverIPs()
+		if err != nil {
+			return false, err
+		}
+
+		for _, ip := range ips {
+			f := FindFailoverIP(list, ip)
+			if f == nil {
+				return false, fmt.Errorf("failover IP %s is not part of the account", ip)
+			}
+
+			if f.Destination != destination {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	}
+}
+
+// waitFor waits for the condition with the client context, backing off from
+// the given interval up to ten times it.
+func (c *client) waitFor(description string, interval, timeout time.Duration, cond Condition) error {
+	w := &Waiter{
+		Description: description,
+		Interval:    interval,
+		Backoff:     1.5,
+		MaxInterval: 10 * interval,
+		Timeout:     timeout,
+	}
+
+	return w.Wait(c.ctx, cond)
+}
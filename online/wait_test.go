@@ -0,0 +1,105 @@
+package online
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testWaiter(timeout time.Duration) *Waiter {
+	return &Waiter{
+		Description: "test",
+		Interval:    time.Millisecond,
+		Timeout:     timeout,
+	}
+}
+
+func TestWaiterConditionMet(t *testing.T) {
+	var checks int
+	err := testWaiter(time.Second).Wait(context.Background(), func() (bool, error) {
+		checks++
+		return checks == 3, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, checks)
+}
+
+func TestWaiterTimeout(t *testing.T) {
+	err := testWaiter(10*time.Millisecond).Wait(context.Background(), func() (bool, error) {
+		return false, nil
+	})
+
+	assert.EqualError(t, err, "timeout waiting for test")
+}
+
+func TestWaiterConditionError(t *testing.T) {
+	var checks int
+	err := testWaiter(time.Second).Wait(context.Background(), func() (bool, error) {
+		checks++
+		return false, errors.New("failed")
+	})
+
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, 1, checks)
+}
+
+func TestWaiterContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	err := testWaiter(time.Hour).Wait(ctx, func() (bool, error) {
+		return false, nil
+	})
+
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestWaiterBackoff(t *testing.T) {
+	w := &Waiter{Backoff: 2, MaxInterval: 5 * time.Second}
+	assert.Equal(t, 2*time.Second, w.next(time.Second))
+	assert.Equal(t, 5*time.Second, w.next(4*time.Second))
+
+	w = &Waiter{}
+	assert.Equal(t, time.Second, w.next(time.Second))
+}
+
+func TestServerBootModeCondition(t *testing.T) {
+	mode := BootModeNormal
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 42, "boot_mode": "` + mode + `", "power": "ON"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+
+	done, err := ServerBootMode(c, 42, BootModeRescue)()
+	assert.NoError(t, err)
+	assert.False(t, done)
+
+	mode = BootModeRescue
+	done, err = ServerBootMode(c, 42, BootModeRescue)()
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	done, err = ServerPower(c, 42, PowerOn)()
+	assert.NoError(t, err)
+	assert.True(t, done)
+}
+
+func TestRPNv2ActiveCondition(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 42, "status": "ACTIVE", "member": [{"status": "UPDATING"}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("token", &Options{BaseURL: srv.URL})
+	done, err := RPNv2Active(c, 42)()
+	assert.NoError(t, err)
+	assert.False(t, done)
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/src-d/terraform-provider-online/online"
+)
+
+func dataFailoverIPs() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceFailoverIPsRead,
+		Schema: map[string]*schema.Schema{
+			"unassigned": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Description:   "only list the failover IPs not routed to any server",
+				ConflictsWith: []string{"destination_server_id"},
+			},
+			"destination_server_id": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "only list the failover IPs routed to this server",
+				ConflictsWith: []string{"unassigned"},
+			},
+			"ips": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "matching failover IPs",
+			},
+			"destination_ips": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "addresses of the servers the failover IPs are routed to",
+			},
+			"macs": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "virtual MACs of the failover IPs",
+			},
+			"types": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "types of the failover IPs",
+			},
+		},
+	}
+}
+
+func dataSourceFailoverIPsRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+
+	list, err := c.ListFailoverIPs()
+	if err != nil {
+		return err
+	}
+
+	unassigned := d.Get("unassigned").(bool)
+
+	var destination string
+	if id, ok := d.GetOk("destination_server_id"); ok {
+		s, err := c.Server(id.(int))
+		if err != nil {
+			return err
+		}
+
+		destination = interfaceAddress(s, online.Public)
+		if destination == "" {
+			return fmt.Errorf("Server %d has no public address", id.(int))
+		}
+	}
+
+	var ips, destinations, macs, types []string
+	for _, f := range list {
+		if unassigned && f.Destination != "" {
+			continue
+		}
+
+		if destination != "" && f.Destination != destination {
+			continue
+		}
+
+		ips = append(ips, f.Source)
+		destinations = append(destinations, f.Destination)
+		macs = append(macs, f.MAC)
+		types = append(types, f.Type)
+	}
+
+	d.Set("ips", ips)
+	d.Set("destination_ips", destinations)
+	d.Set("macs", macs)
+	d.Set("types", types)
+	d.SetId(hashcode.Strings(ips))
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestDataFailoverIPs(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				data "online_failover_ips" "test" {
+					unassigned            = true
+					destination_server_id = 1234
+				}
+				`,
+				ExpectError: regexp.MustCompile("conflicts with"),
+			},
+			{
+				Config: `
+				data "online_failover_ips" "test" {}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_failover_ips.test", "ips.#", "3"),
+					resource.TestCheckResourceAttr("data.online_failover_ips.test", "ips.2", "127.0.0.3"),
+					resource.TestCheckResourceAttr("data.online_failover_ips.test", "destination_ips.2", "8.8.8.8"),
+					resource.TestCheckResourceAttr("data.online_failover_ips.test", "macs.2", "52:54:00:aa:bb:cc"),
+					resource.TestCheckResourceAttr("data.online_failover_ips.test", "types.2", "ipv4"),
+				),
+			},
+			{
+				Config: `
+				data "online_failover_ips" "test" {
+					unassigned = true
+				}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_failover_ips.test", "ips.#", "2"),
+					resource.TestCheckResourceAttr("data.online_failover_ips.test", "ips.0", "127.0.0.1"),
+					resource.TestCheckResourceAttr("data.online_failover_ips.test", "ips.1", "127.0.0.2"),
+				),
+			},
+			{
+				Config: `
+				data "online_failover_ips" "test" {
+					destination_server_id = 1234
+				}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_failover_ips.test", "ips.#", "1"),
+					resource.TestCheckResourceAttr("data.online_failover_ips.test", "ips.0", "127.0.0.3"),
+				),
+			},
+		},
+	})
+}
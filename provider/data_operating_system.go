@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/src-d/terraform-provider-online/online"
+)
+
+func dataOperatingSystem() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceOperatingSystemRead,
+		Schema: map[string]*schema.Schema{
+			"server": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				ForceNew:    true,
+				Description: "server the operating system is installed on",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "name of the desired operating system, case insensitive",
+			},
+			"version": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "exact version of the desired operating system, the newest one is used if not set",
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "type of the desired operating system, eg: server or virtualization",
+			},
+			"arch": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "architecture of the desired operating system, eg: 64 bits",
+			},
+			"os_id": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "id of the operating system, to use in the online_server os block",
+			},
+			"release": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "release date of the operating system",
+			},
+			"end_of_life": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "end of life date of the operating system",
+			},
+		},
+	}
+}
+
+func dataSourceOperatingSystemRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+
+	server := d.Get("server").(int)
+	list, err := c.OperatingSystems(server)
+	if err != nil {
+		return err
+	}
+
+	os, err := selectOperatingSystem(list, &online.OperatingSystem{
+		Name:    d.Get("name").(string),
+		Version: d.Get("version").(string),
+		Type:    d.Get("type").(string),
+		Arch:    d.Get("arch").(string),
+	})
+	if err != nil {
+		return err
+	}
+
+	d.Set("os_id", os.ID)
+	d.Set("name", os.Name)
+	d.Set("version", os.Version)
+	d.Set("type", os.Type)
+	d.Set("arch", os.Arch)
+	d.Set("release", os.Release)
+	d.Set("end_of_life", os.EndOfLife)
+	d.SetId(strconv.Itoa(os.ID))
+
+	return nil
+}
+
+// selectOperatingSystem returns the operating system matching every non
+// empty field of want, the one with the newest version if several match.
+func selectOperatingSystem(list []*online.OperatingSystem,
+	want *online.OperatingSystem) (*online.OperatingSystem, error) {
+	var selected *online.OperatingSystem
+	var options []string
+	for _, os := range list {
+		options = append(options, fmt.Sprintf("%s %s %s %s (%d)", os.Name, os.Version, os.Type, os.Arch, os.ID))
+
+		if !matchesFilter(want.Name, os.Name) ||
+			!matchesFilter(want.Type, os.Type) ||
+			!matchesFilter(want.Arch, os.Arch) ||
+			(want.Version != "" && want.Version != os.Version) {
+			continue
+		}
+
+		if selected == nil || compareVersions(os.Version, selected.Version) > 0 {
+			selected = os
+		}
+	}
+
+	if selected == nil {
+		return nil, fmt.Errorf("No operating system found for requirements, options are: %s", strings.Join(options, ","))
+	}
+
+	return selected, nil
+}
+
+func matchesFilter(want, value string) bool {
+	return want == "" || strings.EqualFold(want, value)
+}
+
+// compareVersions compares two versions number by number, eg: 18.04 is newer
+// than 9.8, returning a positive value if a is newer than b.
+func compareVersions(a, b string) int {
+	notDigit := func(r rune) bool { return !unicode.IsDigit(r) }
+	as, bs := strings.FieldsFunc(a, notDigit), strings.FieldsFunc(b, notDigit)
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, _ := strconv.Atoi(as[i])
+		bn, _ := strconv.Atoi(bs[i])
+		if an != bn {
+			return an - bn
+		}
+	}
+
+	return len(as) - len(bs)
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/src-d/terraform-provider-online/online"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataOperatingSystem(t *testing.T) {
+	onlineClientMock.On("OperatingSystems", 456).Return([]*online.OperatingSystem{
+		{ID: 1, Name: "Ubuntu", Version: "16.04", Type: "server", Arch: "64 bits"},
+		{ID: 2, Name: "Ubuntu", Version: "18.04", Type: "server", Arch: "64 bits", Release: "2018-04-26"},
+		{ID: 3, Name: "Ubuntu", Version: "18.04", Type: "server", Arch: "32 bits"},
+		{ID: 4, Name: "Proxmox", Version: "5.4", Type: "virtualization", Arch: "64 bits"},
+	}, nil)
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				data "online_operating_system" "test" {
+					name   = "ubuntu"
+					arch   = "64 bits"
+					server = 456
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_operating_system.test", "os_id", "2"),
+					resource.TestCheckResourceAttr("data.online_operating_system.test", "name", "Ubuntu"),
+					resource.TestCheckResourceAttr("data.online_operating_system.test", "version", "18.04"),
+					resource.TestCheckResourceAttr("data.online_operating_system.test", "release", "2018-04-26"),
+				),
+			},
+			{
+				Config: `
+				data "online_operating_system" "test" {
+					name    = "ubuntu"
+					version = "16.04"
+					server  = 456
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_operating_system.test", "os_id", "1"),
+				),
+			},
+			{
+				Config: `
+				data "online_operating_system" "test" {
+					type   = "virtualization"
+					server = 456
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_operating_system.test", "os_id", "4"),
+					resource.TestCheckResourceAttr("data.online_operating_system.test", "name", "Proxmox"),
+				),
+			},
+			{
+				Config: `
+				data "online_operating_system" "test" {
+					name   = "centos"
+					server = 456
+				}
+			`,
+				ExpectError: regexp.MustCompile(`No operating system found for requirements, options are: Ubuntu 16.04`),
+			},
+		},
+	})
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.True(t, compareVersions("18.04", "9.8") > 0)
+	assert.True(t, compareVersions("7.6", "7.10") < 0)
+	assert.True(t, compareVersions("2016", "2012 R2") > 0)
+	assert.True(t, compareVersions("18.04.2", "18.04") > 0)
+	assert.Equal(t, 0, compareVersions("18.04", "18.04"))
+}
@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/src-d/terraform-provider-online/online"
+)
+
+func dataRPNv2() *schema.Resource {
+	s := rpnv2ComputedSchema()
+	s["group_id"] = &schema.Schema{
+		Type:          schema.TypeInt,
+		Optional:      true,
+		Computed:      true,
+		Description:   "id of the rpnv2 group",
+		ConflictsWith: []string{"name"},
+	}
+	s["name"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		Description:   "exact name of the rpnv2 group",
+		ConflictsWith: []string{"group_id"},
+	}
+
+	return &schema.Resource{
+		Read:   dataSourceRPNv2Read,
+		Schema: s,
+	}
+}
+
+func dataSourceRPNv2Read(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+
+	idInterface, hasID := d.GetOk("group_id")
+	nameInterface, hasName := d.GetOk("name")
+
+	var rpnv2 *online.RPNv2
+	var err error
+	switch {
+	case hasID:
+		rpnv2, err = c.RPNv2(idInterface.(int))
+	case hasName:
+		rpnv2, err = rpnv2ByName(c, nameInterface.(string))
+	default:
+		return errors.New("Need either a group_id or a name")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(strconv.Itoa(rpnv2.ID))
+	d.Set("group_id", rpnv2.ID)
+	d.Set("name", rpnv2.Name)
+	setRPNv2Attributes(rpnv2, d)
+
+	return nil
+}
+
+// rpnv2ByName returns the only group with the given name, unlike
+// RPNv2ByName it fails when several groups share it.
+func rpnv2ByName(c online.Client, name string) (*online.RPNv2, error) {
+	list, err := c.ListRPNv2()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*online.RPNv2
+	for _, rpnv2 := range list {
+		if rpnv2.Name == name {
+			found = append(found, rpnv2)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("No RPNv2 group found with name %q", name)
+	case 1:
+		return found[0], nil
+	}
+
+	var ids []string
+	for _, rpnv2 := range found {
+		ids = append(ids, strconv.Itoa(rpnv2.ID))
+	}
+
+	return nil, fmt.Errorf("%d RPNv2 groups found with name %q, ids are: %s", len(found), name, strings.Join(ids, ","))
+}
+
+func rpnv2ComputedSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"type": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "type of the rpnv2 group, eg: STANDARD or QINQ",
+		},
+		"status": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "status of the rpnv2 group",
+		},
+		"compatibility_rpn_v1": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "whether the rpnv2 group is reachable from RPNv1 servers",
+		},
+		"members": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "members of the rpnv2 group",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"server_id": {
+						Type:        schema.TypeInt,
+						Computed:    true,
+						Description: "id of the member server",
+					},
+					"ip": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "address of the member server",
+					},
+					"vlan": {
+						Type:        schema.TypeInt,
+						Computed:    true,
+						Description: "vlan id of the member, 0 in QINQ groups",
+					},
+					"status": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "status of the member",
+					},
+				},
+			},
+		},
+	}
+}
+
+func setRPNv2Attributes(rpnv2 *online.RPNv2, d *schema.ResourceData) {
+	for k, v := range flattenRPNv2(rpnv2) {
+		d.Set(k, v)
+	}
+}
+
+// flattenRPNv2 returns the attributes of rpnv2ComputedSchema
+func flattenRPNv2(rpnv2 *online.RPNv2) map[string]interface{} {
+	var members []map[string]interface{}
+	for _, m := range rpnv2.Members {
+		members = append(members, map[string]interface{}{
+			"server_id": m.Linked.ID,
+			"ip":        m.Linked.IP,
+			"vlan":      rpnv2MemberVLAN(rpnv2, m),
+			"status":    m.Status,
+		})
+	}
+
+	return map[string]interface{}{
+		"type":                 string(rpnv2.Type),
+		"status":               rpnv2.Status,
+		"compatibility_rpn_v1": rpnv2.CompatibilityRPNv1,
+		"members":              members,
+	}
+}
@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/src-d/terraform-provider-online/online"
+)
+
+func dataRPNv2Groups() *schema.Resource {
+	group := rpnv2ComputedSchema()
+	group["group_id"] = &schema.Schema{
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "id of the rpnv2 group",
+	}
+	group["name"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "name of the rpnv2 group",
+	}
+
+	return &schema.Resource{
+		Read: dataSourceRPNv2GroupsRead,
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "type of the rpnv2 groups",
+				ValidateFunc: validateStringIn(online.RPNv2Types...),
+			},
+			"server_id": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "id of a server member of the rpnv2 groups",
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: "ids of the matching rpnv2 groups",
+			},
+			"groups": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Resource{Schema: group},
+				Description: "matching rpnv2 groups",
+			},
+		},
+	}
+}
+
+func dataSourceRPNv2GroupsRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+
+	list, err := c.ListRPNv2()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	rpnv2Type := d.Get("type").(string)
+	serverID := d.Get("server_id").(int)
+
+	var ids []int
+	var groups []map[string]interface{}
+	var hashes []string
+	for _, rpnv2 := range list {
+		if rpnv2Type != "" && string(rpnv2.Type) != rpnv2Type {
+			continue
+		}
+
+		if serverID != 0 && rpnv2.MemberByServerID(serverID) == nil {
+			continue
+		}
+
+		group := flattenRPNv2(rpnv2)
+		group["group_id"] = rpnv2.ID
+		group["name"] = rpnv2.Name
+
+		ids = append(ids, rpnv2.ID)
+		groups = append(groups, group)
+		hashes = append(hashes, strconv.Itoa(rpnv2.ID))
+	}
+
+	d.Set("ids", ids)
+	d.Set("groups", groups)
+	d.SetId(hashcode.Strings(hashes))
+
+	return nil
+}
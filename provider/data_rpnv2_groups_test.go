@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestDataRPNv2Groups(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				data "online_rpnv2_groups" "test" {
+					type = "SPECIAL"
+				}
+				`,
+				ExpectError: regexp.MustCompile("type must be one of STANDARD, QINQ, DEMO"),
+			},
+			{
+				Config: `
+				data "online_rpnv2_groups" "test" {}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "ids.#", "4"),
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "groups.#", "4"),
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "groups.0.name", "backend"),
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "groups.0.members.0.ip", "10.0.0.1"),
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "groups.0.members.0.vlan", "2000"),
+				),
+			},
+			{
+				Config: `
+				data "online_rpnv2_groups" "test" {
+					type = "QINQ"
+				}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "ids.0", "41"),
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "groups.0.group_id", "41"),
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "groups.0.compatibility_rpn_v1", "true"),
+				),
+			},
+			{
+				Config: `
+				data "online_rpnv2_groups" "test" {
+					server_id = 2
+				}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "ids.#", "2"),
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "ids.0", "40"),
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "ids.1", "41"),
+				),
+			},
+			{
+				Config: `
+				data "online_rpnv2_groups" "test" {
+					type      = "STANDARD"
+					server_id = 2
+				}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.online_rpnv2_groups.test", "ids.0", "40"),
+				),
+			},
+		},
+	})
+}
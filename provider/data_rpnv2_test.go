@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/src-d/terraform-provider-online/online"
+)
+
+func testRPNv2Member(serverID int, ip string, vlan int) *online.Member {
+	m := &online.Member{Status: "ACTIVE", VLAN: vlan}
+	m.Linked.ID = serverID
+	m.Linked.IP = ip
+	return m
+}
+
+// testRPNv2Groups are the RPNv2 groups listed by the mocked account
+var testRPNv2Groups = []*online.RPNv2{
+	{ID: 40, Name: "backend", Status: "ACTIVE", Type: online.Standard, Members: []*online.Member{
+		testRPNv2Member(1, "10.0.0.1", 2000),
+		testRPNv2Member(2, "10.0.0.2", 2001),
+	}},
+	{ID: 41, Name: "storage", Status: "UPDATING", Type: online.QinQ, CompatibilityRPNv1: true, Members: []*online.Member{
+		testRPNv2Member(2, "10.0.0.2", 5),
+	}},
+	{ID: 42, Name: "duplicate", Status: "ACTIVE", Type: online.Standard},
+	{ID: 43, Name: "duplicate", Status: "ACTIVE", Type: online.Standard},
+}
+
+func init() {
+	onlineClientMock.On("ListRPNv2").Return(testRPNv2Groups, nil)
+	onlineClientMock.On("RPNv2", 41).Return(testRPNv2Groups[1], nil)
+}
+
+func TestDataRPNv2(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				data "online_rpnv2" "test" {
+					group_id = 41
+					name     = "storage"
+				}
+				`,
+				ExpectError: regexp.MustCompile("conflicts with"),
+			},
+			{
+				Config: `
+				data "online_rpnv2" "test" {
+					name = "duplicate"
+				}
+				`,
+				ExpectError: regexp.MustCompile(`2 RPNv2 groups found with name "duplicate", ids are: 42,43`),
+			},
+			{
+				Config: `
+				data "online_rpnv2" "test" {
+					name = "backend"
+				}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "id", "40"),
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "group_id", "40"),
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "type", "STANDARD"),
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "status", "ACTIVE"),
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "members.#", "2"),
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "members.1.server_id", "2"),
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "members.1.ip", "10.0.0.2"),
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "members.1.vlan", "2001"),
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "members.1.status", "ACTIVE"),
+				),
+			},
+			{
+				Config: `
+				data "online_rpnv2" "test" {
+					group_id = 41
+				}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "name", "storage"),
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "type", "QINQ"),
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "status", "UPDATING"),
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "compatibility_rpn_v1", "true"),
+					resource.TestCheckResourceAttr("data.online_rpnv2.test", "members.0.vlan", "0"),
+				),
+			},
+		},
+	})
+}
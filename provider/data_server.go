@@ -0,0 +1,327 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/src-d/terraform-provider-online/online"
+)
+
+func dataServer() *schema.Resource {
+	s := serverComputedSchema()
+	s["os"] = installedOSSchema()
+	s["server_id"] = &schema.Schema{
+		Type:          schema.TypeInt,
+		Optional:      true,
+		Computed:      true,
+		Description:   "id of the server",
+		ConflictsWith: []string{"hostname"},
+	}
+	s["hostname"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		Description:   "exact hostname of the server",
+		ConflictsWith: []string{"server_id"},
+	}
+
+	return &schema.Resource{
+		Read:   dataSourceServerRead,
+		Schema: s,
+	}
+}
+
+func dataSourceServerRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+
+	idInterface, hasID := d.GetOk("server_id")
+	hostnameInterface, hasHostname := d.GetOk("hostname")
+
+	var s *online.Server
+	var err error
+	switch {
+	case hasID:
+		s, err = c.Server(idInterface.(int))
+	case hasHostname:
+		s, err = serverByHostname(c, hostnameInterface.(string))
+	default:
+		return errors.New("Need either a server_id or a hostname")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(strconv.Itoa(s.ID))
+	d.Set("server_id", s.ID)
+	d.Set("hostname", s.Hostname)
+	d.Set("os", flattenOS(s.OS))
+	setServerAttributes(s, d)
+
+	return nil
+}
+
+func serverByHostname(c online.Client, hostname string) (*online.Server, error) {
+	servers, err := c.ListServers()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*online.Server
+	for _, s := range servers {
+		if s.Hostname == hostname {
+			found = append(found, s)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("No server found with hostname %q", hostname)
+	case 1:
+		return found[0], nil
+	}
+
+	var ids []string
+	for _, s := range found {
+		ids = append(ids, strconv.Itoa(s.ID))
+	}
+
+	return nil, fmt.Errorf("%d servers found with hostname %q, ids are: %s", len(found), hostname, strings.Join(ids, ","))
+}
+
+func installedOSSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeMap,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "installed operating system, with its name and version",
+	}
+}
+
+func flattenOS(os *online.OS) map[string]interface{} {
+	if os == nil {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"name":    os.Name,
+		"version": os.Version,
+	}
+}
+
+// serverComputedSchema returns the read-only attributes of a server, as set
+// by setServerAttributes.
+func serverComputedSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"offer": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "offer name of the server",
+		},
+		"power": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "power state of the server",
+		},
+		"boot_mode": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "boot mode of the server",
+		},
+		"last_reboot": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "date of the last reboot of the server",
+		},
+		"location": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "location of the server, with its datacenter, room, rack, block and position",
+		},
+		"anti_ddos": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "whether the anti-DDoS protection is enabled",
+		},
+		"hardware_watch": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "whether the hardware watch is enabled",
+		},
+		"proactive_monitoring": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "whether the proactive monitoring is enabled",
+		},
+		"contacts": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "owner and tech contacts of the server",
+		},
+		"support": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "support level of the server",
+		},
+		"abuse": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "abuse contact of the server",
+		},
+		"interfaces": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        computedInterface(),
+			Description: "every network interface of the server",
+		},
+		"failover_ips": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "failover IPs routed to the server",
+		},
+		"disks": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "API references of the server disks",
+		},
+		"drive_arrays": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        computedDriveArray(),
+			Description: "drive arrays of the server",
+		},
+		"raid_controllers": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "API references of the server RAID controllers",
+		},
+	}
+}
+
+func computedDriveArray() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"raid_level": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "RAID level of the array.",
+			},
+			"raid_controller": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "API reference of the RAID controller managing the array.",
+			},
+			"disks": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "API references of the disks in the array.",
+			},
+		},
+	}
+}
+
+func computedInterface() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Interface type, public or private.",
+			},
+			"address": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Static IPv4 address.",
+			},
+			"mac": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Hardware address of the device.",
+			},
+			"reverse": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Reverse DNS of the address.",
+			},
+			"switch_port_state": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "State of the switch port.",
+			},
+		},
+	}
+}
+
+func setServerAttributes(s *online.Server, d *schema.ResourceData) {
+	d.Set("offer", s.Offer)
+	d.Set("power", s.Power)
+	d.Set("boot_mode", s.BootMode)
+	d.Set("last_reboot", s.LastReboot)
+	d.Set("anti_ddos", s.AntiDDOS)
+	d.Set("hardware_watch", s.HardwareWatch)
+	d.Set("proactive_monitoring", s.ProactiveMonitoring)
+	d.Set("support", s.Support)
+	d.Set("abuse", s.Abuse)
+	d.Set("failover_ips", s.Network.Ipfo)
+	d.Set("disks", flattenRefs(s.Disks))
+	d.Set("raid_controllers", flattenRefs(s.RaidControllers))
+	d.Set("contacts", map[string]interface{}{
+		"owner": s.Contacts.Owner,
+		"tech":  s.Contacts.Tech,
+	})
+
+	var location map[string]interface{}
+	if s.Location != nil {
+		location = map[string]interface{}{
+			"datacenter": s.Location.Datacenter,
+			"room":       s.Location.Room,
+			"rack":       s.Location.Rack,
+			"block":      s.Location.Block,
+			"position":   strconv.Itoa(s.Location.Position),
+		}
+	}
+
+	d.Set("location", location)
+
+	var interfaces []map[string]interface{}
+	for _, iface := range s.IP {
+		interfaces = append(interfaces, map[string]interface{}{
+			"type":              string(iface.Type),
+			"address":           iface.Address,
+			"mac":               strings.ToLower(iface.MAC),
+			"reverse":           iface.Reverse,
+			"switch_port_state": iface.SwitchPortState,
+		})
+	}
+
+	d.Set("interfaces", interfaces)
+
+	var arrays []map[string]interface{}
+	for _, array := range s.DriveArrays {
+		arrays = append(arrays, map[string]interface{}{
+			"raid_level":      array.RaidLevel,
+			"raid_controller": array.RaidController.Ref,
+			"disks":           flattenRefs(array.Disks),
+		})
+	}
+
+	d.Set("drive_arrays", arrays)
+}
+
+func flattenRefs(refs []online.Ref) []string {
+	var list []string
+	for _, r := range refs {
+		list = append(list, r.Ref)
+	}
+
+	return list
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/src-d/terraform-provider-online/online"
+	"github.com/src-d/terraform-provider-online/online/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	onlineClientMock.On("Server", 4242).Return(&online.Server{
+		ID:                  4242,
+		Hostname:            "stg-worker-42",
+		Offer:               "Dedibox XC",
+		OS:                  &online.OS{Name: "Ubuntu", Version: "18.04"},
+		Power:               "ON",
+		BootMode:            "normal",
+		LastReboot:          "2019-05-27T10:00:00.000Z",
+		AntiDDOS:            true,
+		ProactiveMonitoring: true,
+		Location: &online.Location{
+			Datacenter: "DC3",
+			Room:       "4 4-5",
+			Rack:       "E11",
+			Block:      "E",
+			Position:   14,
+		},
+		IP: []*online.Interface{
+			{
+				Address:         "1.2.3.4",
+				MAC:             "AA:BB:CC:DD:EE:FF",
+				Reverse:         "my.dns.address",
+				SwitchPortState: "up",
+				Type:            online.Public,
+			},
+			{
+				Address: "10.2.3.4",
+				MAC:     "00:bb:cc:dd:ee:ff",
+				Type:    online.Private,
+			},
+		},
+	}, nil)
+}
+
+func TestDataServer(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				data "online_server" "test" {
+					server_id = 4242
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_server.test", "hostname", "stg-worker-42"),
+					resource.TestCheckResourceAttr("data.online_server.test", "offer", "Dedibox XC"),
+					resource.TestCheckResourceAttr("data.online_server.test", "os.name", "Ubuntu"),
+					resource.TestCheckResourceAttr("data.online_server.test", "os.version", "18.04"),
+					resource.TestCheckResourceAttr("data.online_server.test", "power", "ON"),
+					resource.TestCheckResourceAttr("data.online_server.test", "boot_mode", "normal"),
+					resource.TestCheckResourceAttr("data.online_server.test", "last_reboot", "2019-05-27T10:00:00.000Z"),
+					resource.TestCheckResourceAttr("data.online_server.test", "anti_ddos", "true"),
+					resource.TestCheckResourceAttr("data.online_server.test", "hardware_watch", "false"),
+					resource.TestCheckResourceAttr("data.online_server.test", "proactive_monitoring", "true"),
+					resource.TestCheckResourceAttr("data.online_server.test", "location.datacenter", "DC3"),
+					resource.TestCheckResourceAttr("data.online_server.test", "location.position", "14"),
+					resource.TestCheckResourceAttr("data.online_server.test", "interfaces.#", "2"),
+					resource.TestCheckResourceAttr("data.online_server.test", "interfaces.0.type", "public"),
+					resource.TestCheckResourceAttr("data.online_server.test", "interfaces.0.mac", "aa:bb:cc:dd:ee:ff"),
+					resource.TestCheckResourceAttr("data.online_server.test", "interfaces.0.reverse", "my.dns.address"),
+					resource.TestCheckResourceAttr("data.online_server.test", "interfaces.0.switch_port_state", "up"),
+					resource.TestCheckResourceAttr("data.online_server.test", "interfaces.1.address", "10.2.3.4"),
+				),
+			},
+		},
+	})
+}
+
+func TestServerByHostname(t *testing.T) {
+	c := new(mock.OnlineClientMock)
+	c.On("ListServers").Return([]*online.Server{
+		{ID: 1, Hostname: "stg-worker-1"},
+		{ID: 2, Hostname: "prod-db-1"},
+		{ID: 3, Hostname: "prod-db-1"},
+		{ID: 4, Hostname: "prod-web-1"},
+	}, nil)
+
+	s, err := serverByHostname(c, "stg-worker-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, s.ID)
+
+	_, err = serverByHostname(c, "prod-db")
+	assert.EqualError(t, err, `No server found with hostname "prod-db"`)
+
+	_, err = serverByHostname(c, "prod-db-1")
+	assert.EqualError(t, err, `2 servers found with hostname "prod-db-1", ids are: 2,3`)
+}
@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/src-d/terraform-provider-online/online"
+)
+
+func dataServers() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceServersRead,
+		Schema: map[string]*schema.Schema{
+			"hostname_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "regular expression the server hostnames must match",
+				ValidateFunc: validateRegexp,
+			},
+			"offer": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "offer name of the servers",
+			},
+			"datacenter": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "datacenter of the servers, eg: DC3",
+			},
+			"room": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "room of the servers",
+			},
+			"rack": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "rack of the servers",
+			},
+			"power": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "power state of the servers, eg: ON or OFF",
+			},
+			"boot_mode": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "boot mode of the servers, eg: normal or rescue",
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: "ids of the matching servers",
+			},
+			"hostnames": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "hostnames of the matching servers",
+			},
+			"public_ips": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "public addresses of the matching servers",
+			},
+			"private_ips": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "private addresses of the matching servers",
+			},
+		},
+	}
+}
+
+func dataSourceServersRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+
+	servers, err := c.ListServers()
+	if err != nil {
+		return err
+	}
+
+	var hostnameRegexp *regexp.Regexp
+	if v, ok := d.GetOk("hostname_regex"); ok {
+		hostnameRegexp = regexp.MustCompile(v.(string))
+	}
+
+	var ids []int
+	var idStrings, hostnames, publicIPs, privateIPs []string
+	for _, s := range servers {
+		if hostnameRegexp != nil && !hostnameRegexp.MatchString(s.Hostname) {
+			continue
+		}
+
+		if !serverMatchesFilters(s, d) {
+			continue
+		}
+
+		ids = append(ids, s.ID)
+		idStrings = append(idStrings, strconv.Itoa(s.ID))
+		hostnames = append(hostnames, s.Hostname)
+		publicIPs = append(publicIPs, interfaceAddress(s, online.Public))
+		privateIPs = append(privateIPs, interfaceAddress(s, online.Private))
+	}
+
+	d.Set("ids", ids)
+	d.Set("hostnames", hostnames)
+	d.Set("public_ips", publicIPs)
+	d.Set("private_ips", privateIPs)
+	d.SetId(hashcode.Strings(idStrings))
+
+	return nil
+}
+
+func serverMatchesFilters(s *online.Server, d *schema.ResourceData) bool {
+	var location online.Location
+	if s.Location != nil {
+		location = *s.Location
+	}
+
+	filters := map[string]string{
+		"offer":      s.Offer,
+		"datacenter": location.Datacenter,
+		"room":       location.Room,
+		"rack":       location.Rack,
+		"power":      s.Power,
+		"boot_mode":  s.BootMode,
+	}
+
+	for key, value := range filters {
+		want, ok := d.GetOk(key)
+		if ok && !strings.EqualFold(want.(string), value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// interfaceAddress returns the address of the server interface with the
+// given type, or an empty string if the server has none.
+func interfaceAddress(s *online.Server, t online.InterfaceType) string {
+	i := s.InterfaceByType(t)
+	if i == nil {
+		return ""
+	}
+
+	return i.Address
+}
+
+func validateRegexp(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := regexp.Compile(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%s must be a valid regular expression: %s", key, err))
+	}
+
+	return
+}
@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/src-d/terraform-provider-online/online"
+)
+
+func TestDataServers(t *testing.T) {
+	onlineClientMock.On("ListServers").Return([]*online.Server{
+		{
+			ID:       1,
+			Hostname: "stg-worker-1",
+			Offer:    "Dedibox XC",
+			Power:    "ON",
+			BootMode: "normal",
+			Location: &online.Location{Datacenter: "DC3", Room: "4 4-5", Rack: "E11"},
+			IP: []*online.Interface{
+				{Address: "1.2.3.4", Type: online.Public},
+				{Address: "10.2.3.4", Type: online.Private},
+			},
+		},
+		{
+			ID:       2,
+			Hostname: "stg-worker-2",
+			Offer:    "Dedibox XC",
+			Power:    "OFF",
+			BootMode: "rescue",
+			Location: &online.Location{Datacenter: "DC2", Room: "101", Rack: "A1"},
+			IP: []*online.Interface{
+				{Address: "1.2.3.5", Type: online.Public},
+			},
+		},
+		{
+			ID:       3,
+			Hostname: "prod-db-1",
+			Offer:    "Dedibox LT",
+			Power:    "ON",
+			BootMode: "normal",
+			Location: &online.Location{Datacenter: "DC3", Room: "4 4-5", Rack: "E12"},
+			IP: []*online.Interface{
+				{Address: "1.2.3.6", Type: online.Public},
+			},
+		},
+	}, nil)
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				data "online_servers" "test" {
+					hostname_regex = "^stg-"
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_servers.test", "ids.#", "2"),
+					resource.TestCheckResourceAttr("data.online_servers.test", "ids.0", "1"),
+					resource.TestCheckResourceAttr("data.online_servers.test", "ids.1", "2"),
+					resource.TestCheckResourceAttr("data.online_servers.test", "hostnames.1", "stg-worker-2"),
+					resource.TestCheckResourceAttr("data.online_servers.test", "public_ips.1", "1.2.3.5"),
+					resource.TestCheckResourceAttr("data.online_servers.test", "private_ips.0", "10.2.3.4"),
+					resource.TestCheckResourceAttr("data.online_servers.test", "private_ips.1", ""),
+				),
+			},
+			{
+				Config: `
+				data "online_servers" "test" {
+					datacenter = "dc3"
+					power      = "ON"
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_servers.test", "ids.#", "2"),
+					resource.TestCheckResourceAttr("data.online_servers.test", "hostnames.0", "stg-worker-1"),
+					resource.TestCheckResourceAttr("data.online_servers.test", "hostnames.1", "prod-db-1"),
+				),
+			},
+			{
+				Config: `
+				data "online_servers" "test" {
+					offer = "Dedibox XC"
+					boot_mode = "rescue"
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_servers.test", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.online_servers.test", "ids.0", "2"),
+				),
+			},
+			{
+				Config: `
+				data "online_servers" "test" {
+					rack = "Z99"
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.online_servers.test", "ids.#", "0"),
+				),
+			},
+		},
+	})
+}
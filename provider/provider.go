@@ -1,16 +1,22 @@
 package provider
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/src-d/terraform-provider-online/online"
 )
 
 const TokenEnvVar = "ONLINE_TOKEN"
+const APIURLEnvVar = "ONLINE_API_URL"
 
 // Provider returns the provider schema to Terraform.
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"token": &schema.Schema{
 				Type:        schema.TypeString,
@@ -19,20 +25,129 @@ func Provider() terraform.ResourceProvider {
 				Sensitive:   true,
 				Description: "Online.net private API token, by default the ONLINE_TOKEN environment variable is used.",
 			},
+			"api_url": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(APIURLEnvVar, online.DefaultBaseURL),
+				Description: "Online.net API base URL, by default the ONLINE_API_URL environment variable or " +
+					"https://api.online.net is used.",
+			},
+			"retry_max_attempts": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     online.DefaultRetryPolicy.MaxAttempts,
+				Description: "Maximum number of times a request failing with a transient error is sent, 1 disables retries.",
+			},
+			"retry_max_elapsed_time": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      online.DefaultRetryPolicy.MaxElapsedTime.String(),
+				Description:  "Maximum time spent retrying a request failing with a transient error, eg: 2m.",
+				ValidateFunc: validateDuration,
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"online_server":      resourceServer(),
-			"online_rpnv2":       resourceRPNv2(),
-			"online_failover_ip": resourceFailoverIP(),
+			"online_server":                resourceServer(),
+			"online_server_rescue":         resourceServerRescue(),
+			"online_partitioning_template": resourcePartitioningTemplate(),
+			"online_rpnv2":                 resourceRPNv2(),
+			"online_failover_ip":           resourceFailoverIP(),
+			"online_failover_group":        resourceFailoverGroup(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"online_rescue_image": dataRescueImage(),
+			"online_server":           dataServer(),
+			"online_operating_system": dataOperatingSystem(),
+			"online_rescue_image":     dataRescueImage(),
+			"online_servers":          dataServers(),
+			"online_failover_ips":     dataFailoverIPs(),
+			"online_rpnv2":            dataRPNv2(),
+			"online_rpnv2_groups":     dataRPNv2Groups(),
 		},
-		ConfigureFunc: providerConfigure,
 	}
+
+	p.ConfigureFunc = providerConfigure(p)
+	return p
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
+// providerConfigure returns a ConfigureFunc building a client bound to the
+// provider stop context, so any call in progress is aborted when Terraform is
+// interrupted.
+func providerConfigure(p *schema.Provider) schema.ConfigureFunc {
+	return func(d *schema.ResourceData) (interface{}, error) {
+		c, err := newClient(d)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.WithContext(p.StopContext()), nil
+	}
+}
+
+func newClient(d *schema.ResourceData) (online.Client, error) {
 	token := d.Get("token").(string)
-	return online.NewClient(token), nil
+
+	retry := online.DefaultRetryPolicy
+	retry.MaxAttempts = d.Get("retry_max_attempts").(int)
+	elapsed, err := time.ParseDuration(d.Get("retry_max_elapsed_time").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	retry.MaxElapsedTime = elapsed
+	return online.NewClient(token, &online.Options{
+		BaseURL: d.Get("api_url").(string),
+		Retry:   &retry,
+	}), nil
+}
+
+func validateDuration(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := time.ParseDuration(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%s must be a duration, eg: 30s or 5m: %s", key, err))
+	}
+
+	return
+}
+
+// validateStringIn returns a ValidateFunc checking the value is one of the
+// given ones.
+func validateStringIn(values ...string) schema.SchemaValidateFunc {
+	return func(val interface{}, key string) (warns []string, errs []error) {
+		v := val.(string)
+		for _, valid := range values {
+			if v == valid {
+				return
+			}
+		}
+
+		errs = append(errs, fmt.Errorf("%s must be one of %s, got: %q", key, strings.Join(values, ", "), v))
+		return
+	}
+}
+
+// hostnameLabel matches one label of a hostname
+var hostnameLabel = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// validateHostname checks the value is a fully qualified hostname, with or
+// without its trailing dot.
+func validateHostname(val interface{}, key string) (warns []string, errs []error) {
+	v := strings.TrimSuffix(val.(string), ".")
+	labels := strings.Split(v, ".")
+	if len(v) > 253 || len(labels) < 2 {
+		errs = append(errs, fmt.Errorf("%s must be a fully qualified hostname, got: %q", key, val))
+		return
+	}
+
+	for _, label := range labels {
+		if !hostnameLabel.MatchString(label) {
+			errs = append(errs, fmt.Errorf("%s must be a fully qualified hostname, got: %q", key, val))
+			return
+		}
+	}
+
+	return
+}
+
+// suppressHostnameDiff ignores the case and the trailing dot of hostnames.
+func suppressHostnameDiff(k, old, new string, d *schema.ResourceData) bool {
+	return strings.EqualFold(strings.TrimSuffix(old, "."), strings.TrimSuffix(new, "."))
 }
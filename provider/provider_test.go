@@ -8,6 +8,7 @@ import (
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/src-d/terraform-provider-online/online"
 	"github.com/src-d/terraform-provider-online/online/mock"
 )
 
@@ -51,6 +52,17 @@ func providerConfigureMock(d *schema.ResourceData) (interface{}, error) {
 	return onlineClientMock, nil
 }
 
+// testMockProvidersWith returns providers using the given client, for tests
+// whose expectations would shadow the ones of other tests on the shared mock.
+func testMockProvidersWith(c online.Client) map[string]terraform.ResourceProvider {
+	provider := Provider().(*schema.Provider)
+	provider.ConfigureFunc = func(*schema.ResourceData) (interface{}, error) {
+		return c, nil
+	}
+
+	return map[string]terraform.ResourceProvider{"online": provider}
+}
+
 func TestProvider(t *testing.T) {
 	if err := Provider().(*schema.Provider).InternalValidate(); err != nil {
 		t.Fatalf("err: %s", err)
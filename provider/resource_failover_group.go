@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/src-d/terraform-provider-online/online"
+)
+
+func resourceFailoverGroup() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceFailoverGroupCreate,
+		Read:   resourceFailoverGroupRead,
+		Update: resourceFailoverGroupUpdate,
+		Delete: resourceFailoverGroupDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(failoverIPTimeout),
+			Update: schema.DefaultTimeout(failoverIPTimeout),
+			Delete: schema.DefaultTimeout(failoverIPTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"ips": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateIP},
+				Description: "failover IPs moved together",
+			},
+			"destination_server_id": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				Description: "id of the server to route the failover IPs to",
+			},
+			"pacing": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "10s",
+				Description:  "delay between two failover IP changes, the API rejects changes made too quickly",
+				ValidateFunc: validateDuration,
+			},
+		},
+	}
+}
+
+func validateIP(val interface{}, key string) (warns []string, errs []error) {
+	if net.ParseIP(val.(string)) == nil {
+		errs = append(errs, fmt.Errorf("%s must be an IP address, got: %q", key, val))
+	}
+
+	return
+}
+
+func resourceFailoverGroupCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+	ips := expandStringSet(d.Get("ips").(*schema.Set))
+
+	if err := moveFailoverGroup(c, d, ips, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return err
+	}
+
+	d.SetId(hashcode.Strings(ips))
+	return resourceFailoverGroupRead(d, meta)
+}
+
+func resourceFailoverGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+	wait := d.Timeout(schema.TimeoutUpdate)
+
+	// the IPs leaving the group are unrouted, like a destroyed online_failover_ip
+	old, new := d.GetChange("ips")
+	if removed := expandStringSet(old.(*schema.Set).Difference(new.(*schema.Set))); len(removed) != 0 {
+		if err := c.MoveFailoverIPs(removed, "", failoverGroupPacing(d), wait); err != nil {
+			return err
+		}
+	}
+
+	if err := moveFailoverGroup(c, d, expandStringSet(new.(*schema.Set)), wait); err != nil {
+		return err
+	}
+
+	return resourceFailoverGroupRead(d, meta)
+}
+
+// moveFailoverGroup routes the IPs to the public address of the destination
+// server.
+func moveFailoverGroup(c online.Client, d *schema.ResourceData, ips []string, wait time.Duration) error {
+	id := d.Get("destination_server_id").(int)
+	s, err := c.Server(id)
+	if err != nil {
+		return err
+	}
+
+	destination := interfaceAddress(s, online.Public)
+	if destination == "" {
+		return fmt.Errorf("Server %d has no public address", id)
+	}
+
+	return c.MoveFailoverIPs(ips, destination, failoverGroupPacing(d), wait)
+}
+
+func failoverGroupPacing(d *schema.ResourceData) time.Duration {
+	// the value is checked by validateDuration
+	pacing, _ := time.ParseDuration(d.Get("pacing").(string))
+	return pacing
+}
+
+func resourceFailoverGroupRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+
+	list, err := c.ListFailoverIPs()
+	if err != nil {
+		return err
+	}
+
+	// IPs not part of the account anymore are dropped, the next apply then
+	// fails to move them as long as they are in the configuration
+	var ips []string
+	destinations := make(map[string]bool)
+	for _, ip := range expandStringSet(d.Get("ips").(*schema.Set)) {
+		f := online.FindFailoverIP(list, ip)
+		if f == nil {
+			continue
+		}
+
+		ips = append(ips, ip)
+		destinations[f.Destination] = true
+	}
+
+	if len(ips) == 0 {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("ips", ips)
+
+	// IPs split between several destinations are moved again on the next apply
+	if len(destinations) != 1 {
+		d.Set("destination_server_id", 0)
+		return nil
+	}
+
+	for destination := range destinations {
+		id, err := failoverDestinationServerID(c, d.Get("destination_server_id").(int), destination)
+		if err != nil {
+			return err
+		}
+
+		d.Set("destination_server_id", id)
+	}
+
+	return nil
+}
+
+func resourceFailoverGroupDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+	ips := expandStringSet(d.Get("ips").(*schema.Set))
+
+	return c.MoveFailoverIPs(ips, "", failoverGroupPacing(d), d.Timeout(schema.TimeoutDelete))
+}
+
+// expandStringSet returns the sorted values of a set of strings
+func expandStringSet(s *schema.Set) []string {
+	var values []string
+	for _, v := range s.List() {
+		values = append(values, v.(string))
+	}
+
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/src-d/terraform-provider-online/online"
+	onlinemock "github.com/src-d/terraform-provider-online/online/mock"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResourceFailoverGroup(t *testing.T) {
+	ips := []*online.FailoverIP{{Source: "127.0.0.1"}, {Source: "127.0.0.2"}}
+	move := func(args mock.Arguments) {
+		for _, ip := range args.Get(0).([]string) {
+			if f := online.FindFailoverIP(ips, ip); f != nil {
+				f.Destination = args.String(1)
+			}
+		}
+	}
+
+	server := &online.Server{
+		ID: 1234,
+		IP: []*online.Interface{{Address: "8.8.8.8", Type: online.Public}},
+	}
+
+	c := new(onlinemock.OnlineClientMock)
+	c.On("ListFailoverIPs").Return(ips, nil)
+	c.On("Server", 1234).Return(server, nil)
+	// looked up when an IP is routed away from server 1234
+	c.On("ListServers").Return([]*online.Server{
+		server,
+		{ID: 1235, IP: []*online.Interface{{Address: "8.8.4.4", Type: online.Public}}},
+	}, nil)
+
+	anyDuration := mock.AnythingOfType("time.Duration")
+	both := []string{"127.0.0.1", "127.0.0.2"}
+	c.On("MoveFailoverIPs", both, "8.8.8.8", time.Second, anyDuration).Run(move).Return(nil)
+	c.On("MoveFailoverIPs", []string{"127.0.0.2"}, "", time.Second, anyDuration).
+		Run(move).Return(nil)
+	c.On("MoveFailoverIPs", []string{"127.0.0.1"}, "8.8.8.8", time.Second, anyDuration).
+		Run(move).Return(nil)
+	c.On("MoveFailoverIPs", []string{"127.0.0.1"}, "", time.Second, anyDuration).
+		Run(move).Return(nil)
+
+	config := `
+	resource "online_failover_group" "test" {
+		ips                   = [%s]
+		destination_server_id = 1234
+		pacing                = "1s"
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProvidersWith(c),
+		IsUnitTest: true,
+		CheckDestroy: func(*terraform.State) error {
+			if d := ips[0].Destination; d != "" {
+				return fmt.Errorf("failover IP still routed to %q", d)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(config, `"127.0.0.1", "not-an-ip"`),
+				ExpectError: regexp.MustCompile("must be an IP address"),
+			},
+			{
+				Config: fmt.Sprintf(config, `"127.0.0.2", "127.0.0.1"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_failover_group.test", "ips.#", "2"),
+					resource.TestCheckResourceAttr("online_failover_group.test", "destination_server_id", "1234"),
+					func(*terraform.State) error {
+						c.AssertCalled(t, "MoveFailoverIPs", both, "8.8.8.8", time.Second, anyDuration)
+						return nil
+					},
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, `"127.0.0.1"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_failover_group.test", "ips.#", "1"),
+					func(*terraform.State) error {
+						if d := ips[1].Destination; d != "" {
+							return fmt.Errorf("failover IP left in the group still routed to %q", d)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// one IP moved outside of terraform
+				PreConfig:          func() { ips[0].Destination = "9.9.9.9" },
+				Config:             fmt.Sprintf(config, `"127.0.0.1"`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
@@ -1,19 +1,38 @@
 package provider
 
 import (
+	"context"
 	"errors"
-	"strings"
+	"fmt"
+	"log"
+	"net"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/src-d/terraform-provider-online/online"
 )
 
+// failoverIPTimeout is how long failover IP changes, retries included, are
+// waited for by default
+const failoverIPTimeout = 5 * time.Minute
+
 func resourceFailoverIP() *schema.Resource {
 	return &schema.Resource{
 		Read:   resourceFailoverIPRead,
 		Create: resourceFailoverIPCreate,
 		Delete: resourceFailoverIPDelete,
 		Update: resourceFailoverIPUpdate,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceFailoverIPImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(failoverIPTimeout),
+			Update: schema.DefaultTimeout(failoverIPTimeout),
+			Delete: schema.DefaultTimeout(failoverIPTimeout),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"ip": {
 				Type:        schema.TypeString,
@@ -58,19 +77,102 @@ func resourceFailoverIP() *schema.Resource {
 				Optional:    true,
 				Description: "the generated mac",
 			},
+			"reverse": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				Description:      "reverse DNS of the failover IP, left untouched if not set",
+				ValidateFunc:     validateHostname,
+				DiffSuppressFunc: suppressHostnameDiff,
+			},
 		},
 	}
 }
 
 func resourceFailoverIPRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
 	ip := d.Get("ip").(string)
+
+	list, err := c.ListFailoverIPs()
+	if err != nil {
+		return err
+	}
+
+	f := online.FindFailoverIP(list, ip)
+	if f == nil {
+		log.Printf("[DEBUG] failover IP %s is not part of the account anymore", ip)
+		d.SetId("")
+		return nil
+	}
+
 	d.SetId(ip)
+	d.Set("mac", f.MAC)
+	d.Set("generate_mac", f.MAC != "")
+	d.Set("reverse", f.Reverse)
+
+	// the destination is refreshed in the argument used to set it, an
+	// imported IP uses the server address
+	if id, ok := d.GetOk("destination_server_id"); ok {
+		id, err := failoverDestinationServerID(c, id.(int), f.Destination)
+		if err != nil {
+			return err
+		}
+
+		d.Set("destination_server_id", id)
+		return nil
+	}
 
+	d.Set("destination_server_ip", f.Destination)
 	return nil
 }
 
+// failoverDestinationServerID returns the id of the server whose public
+// address is destination, checking first the server currently in state. It
+// returns 0 if no server of the account matches.
+func failoverDestinationServerID(c online.Client, current int, destination string) (int, error) {
+	if destination == "" {
+		return 0, nil
+	}
+
+	if current != 0 {
+		s, err := c.Server(current)
+		if err != nil && !online.IsNotFound(err) {
+			return 0, err
+		}
+
+		if err == nil && interfaceAddress(s, online.Public) == destination {
+			return current, nil
+		}
+	}
+
+	servers, err := c.ListServers()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, s := range servers {
+		if interfaceAddress(s, online.Public) == destination {
+			return s.ID, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// resourceFailoverIPImport imports a failover IP by its address.
+func resourceFailoverIPImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if net.ParseIP(d.Id()) == nil {
+		return nil, fmt.Errorf("Invalid failover IP %q", d.Id())
+	}
+
+	d.Set("ip", d.Id())
+	d.Set("generate_mac_type", "kvm")
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceFailoverIPCreate(d *schema.ResourceData, meta interface{}) error {
-	c := meta.(online.Client)
+	c, cancel := withTimeout(meta.(online.Client), d.Timeout(schema.TimeoutCreate))
+	defer cancel()
 
 	ip := d.Get("ip").(string)
 	serverIDInterface, hasServerID := d.GetOk("destination_server_id")
@@ -93,7 +195,7 @@ func resourceFailoverIPCreate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	err := c.EditFailoverIP(ip, dstIP)
-	if err != nil && !strings.Contains(err.Error(), "Address already provisioned") {
+	if err != nil && !online.IsAlreadyExists(err) {
 		return err
 	}
 
@@ -105,14 +207,21 @@ func resourceFailoverIPCreate(d *schema.ResourceData, meta interface{}) error {
 		d.Set("mac", mac)
 	}
 
+	if reverse, ok := d.GetOk("reverse"); ok {
+		if err := c.SetFailoverIPReverse(ip, reverse.(string)); err != nil {
+			return err
+		}
+	}
+
 	d.SetId(ip)
 	return nil
 }
 
 func resourceFailoverIPDelete(d *schema.ResourceData, meta interface{}) error {
 	ip := d.Get("ip").(string)
-	_, macExists := d.GetOkExists("mac")
-	c := meta.(online.Client)
+	macExists := d.Get("mac").(string) != ""
+	c, cancel := withTimeout(meta.(online.Client), d.Timeout(schema.TimeoutDelete))
+	defer cancel()
 
 	if macExists {
 		err := c.DeleteMACFailoverIP(ip)
@@ -128,7 +237,8 @@ func resourceFailoverIPDelete(d *schema.ResourceData, meta interface{}) error {
 
 func resourceFailoverIPUpdate(d *schema.ResourceData, meta interface{}) error {
 	ip := d.Get("ip").(string)
-	c := meta.(online.Client)
+	c, cancel := withTimeout(meta.(online.Client), d.Timeout(schema.TimeoutUpdate))
+	defer cancel()
 
 	hasNewMACRequest := d.HasChange("generate_mac")
 
@@ -188,5 +298,18 @@ func resourceFailoverIPUpdate(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
+	if d.HasChange("reverse") {
+		if err := c.SetFailoverIPReverse(ip, d.Get("reverse").(string)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// withTimeout returns a client whose calls are aborted once the timeout
+// expires, cancel must be called to release its resources.
+func withTimeout(c online.Client, timeout time.Duration) (online.Client, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(c.Context(), timeout)
+	return c.WithContext(ctx), cancel
+}
@@ -3,21 +3,44 @@ package provider
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/src-d/terraform-provider-online/online"
+	onlinemock "github.com/src-d/terraform-provider-online/online/mock"
+	"github.com/stretchr/testify/mock"
 
 	"github.com/hashicorp/terraform/helper/resource"
 )
 
+// testFailoverIPs are the failover IPs of the mocked account, updated by the
+// mocked calls
+var testFailoverIPs = []*online.FailoverIP{
+	{Source: "127.0.0.1"},
+	{Source: "127.0.0.2"},
+	{Source: "127.0.0.3", Destination: "8.8.8.8", MAC: "52:54:00:aa:bb:cc", Type: "ipv4"},
+}
+
 func init() {
-	onlineClientMock.On("EditFailoverIP", "127.0.0.1", "8.8.8.8").Return(nil)
-	onlineClientMock.On("EditFailoverIP", "127.0.0.1", "").Return(nil)
-	onlineClientMock.On("GenerateMACFailoverIP", "127.0.0.1", "kvm").Return("ma:ac:te:st", nil)
-	onlineClientMock.On("DeleteMACFailoverIP", "127.0.0.1").Return(nil)
+	route := func(ip *online.FailoverIP) func(mock.Arguments) {
+		return func(args mock.Arguments) { ip.Destination = args.String(1) }
+	}
+	setMAC := func(ip *online.FailoverIP, mac string) func(mock.Arguments) {
+		return func(mock.Arguments) { ip.MAC = mac }
+	}
+
+	onlineClientMock.On("ListFailoverIPs").Return(testFailoverIPs, nil)
+	onlineClientMock.On("EditFailoverIP", "127.0.0.1", "8.8.8.8").Run(route(testFailoverIPs[0])).Return(nil)
+	onlineClientMock.On("EditFailoverIP", "127.0.0.1", "").Run(route(testFailoverIPs[0])).Return(nil)
+	onlineClientMock.On("GenerateMACFailoverIP", "127.0.0.1", "kvm").
+		Run(setMAC(testFailoverIPs[0], "ma:ac:te:st")).Return("ma:ac:te:st", nil)
+	onlineClientMock.On("DeleteMACFailoverIP", "127.0.0.1").Run(setMAC(testFailoverIPs[0], "")).Return(nil)
+	onlineClientMock.On("EditFailoverIP", "127.0.0.2", "8.8.8.8").Run(route(testFailoverIPs[1])).Return(nil)
+	onlineClientMock.On("EditFailoverIP", "127.0.0.2", "").Run(route(testFailoverIPs[1])).Return(nil)
 	onlineClientMock.On("Server", 1234).Return(&online.Server{
+		ID: 1234,
 		IP: []*online.Interface{
 			&online.Interface{
 				Address: "8.8.8.8",
@@ -44,6 +67,11 @@ func TestResourceFailoverIP(t *testing.T) {
 					resource.TestCheckResourceAttr("online_failover_ip.test", "ip", "127.0.0.1"),
 				),
 			},
+			{
+				ResourceName:      "online_failover_ip.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
 			{
 				ImportStateVerify: false,
 				Config: `
@@ -74,6 +102,83 @@ func TestResourceFailoverIP(t *testing.T) {
 	})
 }
 
+func TestResourceFailoverIPDrift(t *testing.T) {
+	ip := &online.FailoverIP{Source: "127.0.0.2"}
+	route := func(args mock.Arguments) { ip.Destination = args.String(1) }
+
+	c := new(onlinemock.OnlineClientMock)
+	c.On("ListFailoverIPs").Return([]*online.FailoverIP{ip}, nil)
+	c.On("EditFailoverIP", "127.0.0.2", "8.8.8.8").Run(route).Return(nil)
+	c.On("EditFailoverIP", "127.0.0.2", "").Run(route).Return(nil)
+	c.On("Server", 1234).Return(&online.Server{
+		ID: 1234,
+		IP: []*online.Interface{{Address: "8.8.8.8", Type: online.Public}},
+	}, nil)
+	// looked up when the failover IP is routed away from server 1234
+	c.On("ListServers").Return([]*online.Server{
+		{ID: 1234, IP: []*online.Interface{{Address: "8.8.8.8", Type: online.Public}}},
+		{ID: 1235, IP: []*online.Interface{{Address: "8.8.4.4", Type: online.Public}}},
+	}, nil)
+
+	config := `
+	resource "online_failover_ip" "test" {
+		ip                    = "127.0.0.2"
+		destination_server_id = 1234
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProvidersWith(c),
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_failover_ip.test", "destination_server_id", "1234"),
+					resource.TestCheckResourceAttr("online_failover_ip.test", "generate_mac", "false"),
+				),
+			},
+			{
+				// rerouted outside of terraform, to a server not in the account
+				PreConfig:          func() { ip.Destination = "9.9.9.9" },
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config,
+				Check: func(*terraform.State) error {
+					if ip.Destination != "8.8.8.8" {
+						return fmt.Errorf("failover IP routed to %q", ip.Destination)
+					}
+					return nil
+				},
+			},
+			{
+				// a MAC generated outside of terraform
+				PreConfig:          func() { ip.MAC = "aa:bb:cc:dd:ee:ff" },
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: func() {
+					ip.MAC = ""
+					// the failover IP left the account
+					ip.Source = "192.0.2.1"
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: func() { ip.Source = "127.0.0.2" },
+				Config:    config,
+			},
+		},
+	})
+}
+
 func TestResourceFailoverIPAcceptance(t *testing.T) {
 	if TestFailoverIP == "" && os.Getenv("TF_ACC") == "1" {
 		t.Fatal("Need ONLINE_FAILOVER_IP to be set")
@@ -131,6 +236,56 @@ func TestResourceFailoverIPAcceptance(t *testing.T) {
 					resource.TestCheckResourceAttrSet("online_failover_ip.test", "mac"),
 				),
 			},
+			{
+				ResourceName:            "online_failover_ip.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"destination_server_id", "destination_server_ip"},
+			},
+		},
+	})
+}
+
+func TestResourceFailoverIPReverse(t *testing.T) {
+	ip := testFailoverIPs[1]
+	setReverse := func(args mock.Arguments) { ip.Reverse = args.String(1) }
+
+	onlineClientMock.On("SetFailoverIPReverse", "127.0.0.2", "mail.example.com").Run(setReverse).Return(nil)
+	onlineClientMock.On("SetFailoverIPReverse", "127.0.0.2", "relay.example.com").Run(setReverse).Return(nil)
+
+	config := `
+	resource "online_failover_ip" "test" {
+		ip                    = "127.0.0.2"
+		destination_server_ip = "8.8.8.8"
+		reverse               = "%s"
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(config, "not a hostname"),
+				ExpectError: regexp.MustCompile("reverse must be a fully qualified hostname"),
+			},
+			{
+				Config: fmt.Sprintf(config, "mail.example.com"),
+				Check:  resource.TestCheckResourceAttr("online_failover_ip.test", "reverse", "mail.example.com"),
+			},
+			{
+				// the API may answer with a trailing dot
+				PreConfig: func() { ip.Reverse = "mail.example.com." },
+				Config:    fmt.Sprintf(config, "MAIL.example.com"),
+				PlanOnly:  true,
+			},
+			{
+				Config: fmt.Sprintf(config, "relay.example.com"),
+				Check: func(*terraform.State) error {
+					onlineClientMock.AssertCalled(t, "SetFailoverIPReverse", "127.0.0.2", "relay.example.com")
+					return nil
+				},
+			},
 		},
 	})
 }
@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/src-d/terraform-provider-online/online"
+)
+
+func resourcePartitioningTemplate() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePartitioningTemplateCreate,
+		Update: resourcePartitioningTemplateUpdate,
+		Read:   resourcePartitioningTemplateRead,
+		Delete: resourcePartitioningTemplateDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "name of the partitioning template",
+			},
+			"partition": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem:        resourcePartition(false),
+				Description: "partitions of the template, in disk order",
+			},
+		},
+	}
+}
+
+// resourcePartition returns the schema of a partition block, forceNew is set
+// on every field when the block is part of a server installation.
+func resourcePartition(forceNew bool) *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"drive_array": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				ForceNew:    forceNew,
+				Default:     0,
+				Description: "Index of the drive array holding the partition.",
+			},
+			"raid_level": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     forceNew,
+				Description:  "RAID level of the drive array, every partition of an array must use the same.",
+				ValidateFunc: validateStringIn(append([]string{""}, online.RaidLevels...)...),
+			},
+			"mount_point": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    forceNew,
+				Description: "Mount point of the partition, not allowed for swap and lvm.",
+			},
+			"file_system": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     forceNew,
+				Description:  "File system of the partition, lvm creates an LVM physical volume.",
+				ValidateFunc: validateStringIn(online.FileSystems...),
+			},
+			"size": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				ForceNew:    forceNew,
+				Default:     0,
+				Description: "Size of the partition in MB, 0 uses the space left on the drive array.",
+			},
+		},
+	}
+}
+
+func expandPartitions(list []interface{}) []*online.Partition {
+	var partitions []*online.Partition
+	for _, item := range list {
+		p := item.(map[string]interface{})
+		partitions = append(partitions, &online.Partition{
+			DriveArray: p["drive_array"].(int),
+			RaidLevel:  p["raid_level"].(string),
+			MountPoint: p["mount_point"].(string),
+			FileSystem: p["file_system"].(string),
+			Size:       p["size"].(int),
+		})
+	}
+
+	return partitions
+}
+
+func flattenPartitions(partitions []*online.Partition) []map[string]interface{} {
+	var list []map[string]interface{}
+	for _, p := range partitions {
+		list = append(list, map[string]interface{}{
+			"drive_array": p.DriveArray,
+			"raid_level":  p.RaidLevel,
+			"mount_point": p.MountPoint,
+			"file_system": p.FileSystem,
+			"size":        p.Size,
+		})
+	}
+
+	return list
+}
+
+func resourcePartitioningTemplateCreate(d *schema.ResourceData, meta interface{}) error {
+	return setPartitioningTemplate(meta.(online.Client), &online.PartitioningTemplate{}, d)
+}
+
+func resourcePartitioningTemplateUpdate(d *schema.ResourceData, meta interface{}) error {
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	return setPartitioningTemplate(meta.(online.Client), &online.PartitioningTemplate{ID: id}, d)
+}
+
+func setPartitioningTemplate(c online.Client, t *online.PartitioningTemplate, d *schema.ResourceData) error {
+	t.Name = d.Get("name").(string)
+	t.Partitions = expandPartitions(d.Get("partition").([]interface{}))
+
+	if err := c.SetPartitioningTemplate(t); err != nil {
+		return err
+	}
+
+	d.SetId(strconv.Itoa(t.ID))
+	return nil
+}
+
+func resourcePartitioningTemplateRead(d *schema.ResourceData, meta interface{}) error {
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	c := meta.(online.Client)
+	t, err := c.PartitioningTemplate(id)
+	if online.IsNotFound(err) {
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("name", t.Name)
+	d.Set("partition", flattenPartitions(t.Partitions))
+	return nil
+}
+
+func resourcePartitioningTemplateDelete(d *schema.ResourceData, meta interface{}) error {
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	c := meta.(online.Client)
+	err = c.DeletePartitioningTemplate(id)
+	if online.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/src-d/terraform-provider-online/online"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResourcePartitioningTemplate(t *testing.T) {
+	stored := &online.PartitioningTemplate{}
+	onlineClientMock.On("SetPartitioningTemplate", mock.AnythingOfType("*online.PartitioningTemplate")).
+		Run(func(args mock.Arguments) {
+			t := args.Get(0).(*online.PartitioningTemplate)
+			if t.ID == 0 {
+				t.ID = 77
+			}
+
+			*stored = *t
+		}).Return(nil)
+	onlineClientMock.On("PartitioningTemplate", 77).Return(stored, nil)
+	onlineClientMock.On("DeletePartitioningTemplate", 77).Return(nil)
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "online_partitioning_template" "test" {
+					name = "invalid"
+
+					partition {
+						mount_point = "/"
+						file_system = "ntfs"
+					}
+				}
+			`,
+				ExpectError: regexp.MustCompile(`file_system must be one of ext3, ext4, xfs, swap, lvm`),
+			},
+			{
+				Config: `
+				resource "online_partitioning_template" "test" {
+					name = "raid1"
+
+					partition {
+						raid_level  = "RAID1"
+						mount_point = "/"
+						file_system = "ext4"
+						size        = 20000
+					}
+
+					partition {
+						raid_level  = "RAID1"
+						file_system = "swap"
+						size        = 4096
+					}
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_partitioning_template.test", "id", "77"),
+					resource.TestCheckResourceAttr("online_partitioning_template.test", "partition.#", "2"),
+					resource.TestCheckResourceAttr("online_partitioning_template.test", "partition.0.mount_point", "/"),
+					resource.TestCheckResourceAttr("online_partitioning_template.test", "partition.1.size", "4096"),
+				),
+			},
+			{
+				Config: `
+				resource "online_partitioning_template" "test" {
+					name = "raid1-lvm"
+
+					partition {
+						raid_level  = "RAID1"
+						mount_point = "/"
+						file_system = "ext4"
+						size        = 20000
+					}
+
+					partition {
+						raid_level  = "RAID1"
+						file_system = "lvm"
+					}
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_partitioning_template.test", "id", "77"),
+					resource.TestCheckResourceAttr("online_partitioning_template.test", "name", "raid1-lvm"),
+					resource.TestCheckResourceAttr("online_partitioning_template.test", "partition.1.file_system", "lvm"),
+				),
+			},
+		},
+	})
+}
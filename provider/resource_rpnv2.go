@@ -2,12 +2,17 @@ package provider
 
 import (
 	"fmt"
+	"log"
+	"strconv"
 	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/src-d/terraform-provider-online/online"
 )
 
+// rpnv2Timeout is how long RPNv2 changes are waited for by default
+const rpnv2Timeout = 10 * time.Minute
+
 func resourceRPNv2() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceRPNv2Create,
@@ -15,6 +20,27 @@ func resourceRPNv2() *schema.Resource {
 		Read:   resourceRPNv2Read,
 		Delete: resourceRPNv2Delete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceRPNv2Import,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(rpnv2Timeout),
+			Update: schema.DefaultTimeout(rpnv2Timeout),
+			Delete: schema.DefaultTimeout(rpnv2Timeout),
+		},
+
+		CustomizeDiff: resourceRPNv2CustomizeDiff,
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceRPNv2V0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceRPNv2StateUpgradeV0,
+			},
+		},
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:        schema.TypeString,
@@ -22,29 +48,71 @@ func resourceRPNv2() *schema.Resource {
 				Description: "name of the rpnv2 group",
 			},
 			"type": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     online.Standard,
-				Description: "rpnv2 group type. Defaults to STANDARD",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      online.Standard,
+				Description:  "rpnv2 group type. Defaults to STANDARD",
+				ValidateFunc: validateStringIn(online.RPNv2Types...),
 			},
 			"vlan": {
 				Type:        schema.TypeInt,
-				Required:    true,
-				Description: "rpnv2 vlan id",
+				Optional:    true,
+				Description: "rpnv2 vlan id, the default vlan of the members",
 			},
 			"server_ids": {
-				Type:        schema.TypeList,
-				Required:    true,
-				Elem:        &schema.Schema{Type: schema.TypeInt},
-				Description: "rpnv2 members server ids",
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeInt},
+				Description:   "rpnv2 members server ids",
+				ConflictsWith: []string{"member"},
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "status of the rpnv2 group",
+			},
+			"compatibility_rpn_v1": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "whether the rpnv2 group is reachable from RPNv1 servers",
+			},
+			"member": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Description:   "rpnv2 members, with their own vlan",
+				ConflictsWith: []string{"server_ids"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"server_id": {
+							Type:        schema.TypeInt,
+							Required:    true,
+							Description: "id of the member server",
+						},
+						"vlan": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							Description:      "vlan id of the member, defaults to the group vlan",
+							DiffSuppressFunc: suppressDefaultVLANDiff,
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "status of the member",
+						},
+					},
+				},
 			},
 		},
 	}
 }
 
 func resourceRPNv2Create(d *schema.ResourceData, meta interface{}) error {
+	c, cancel := withTimeout(meta.(online.Client), d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
 	name := d.Get("name").(string)
-	c := meta.(online.Client)
 	currentRPNv2, err := c.RPNv2ByName(name)
 	if err != nil {
 		return err
@@ -54,71 +122,265 @@ func resourceRPNv2Create(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	newRPNv2 := &online.RPNv2{
-		Name: name,
-		Type: online.RPNv2Type(d.Get("type").(string)),
+		Name:               name,
+		Type:               online.RPNv2Type(d.Get("type").(string)),
+		CompatibilityRPNv1: d.Get("compatibility_rpn_v1").(bool),
+	}
+
+	if err := setRPNv2(c, newRPNv2, d, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return err
 	}
 
-	return setRPNv2(c, newRPNv2, d)
+	return resourceRPNv2Read(d, meta)
 }
 
 func resourceRPNv2Update(d *schema.ResourceData, meta interface{}) error {
-	name := d.Get("name").(string)
-	c := meta.(online.Client)
-	currentRPNv2, err := c.RPNv2ByName(name)
+	id, err := strconv.Atoi(d.Id())
 	if err != nil {
 		return err
 	}
 
-	if currentRPNv2 == nil {
-		return fmt.Errorf("missing RPNv2 group: %q", name)
-	}
+	c, cancel := withTimeout(meta.(online.Client), d.Timeout(schema.TimeoutUpdate))
+	defer cancel()
 
 	newRPNv2 := &online.RPNv2{
-		ID:   currentRPNv2.ID,
-		Name: name,
-		Type: online.RPNv2Type(d.Get("type").(string)),
+		ID:                 id,
+		Name:               d.Get("name").(string),
+		Type:               online.RPNv2Type(d.Get("type").(string)),
+		CompatibilityRPNv1: d.Get("compatibility_rpn_v1").(bool),
 	}
 
-	return setRPNv2(c, newRPNv2, d)
+	if err := setRPNv2(c, newRPNv2, d, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return err
+	}
+
+	return resourceRPNv2Read(d, meta)
 }
 
-func setRPNv2(c online.Client, rpnv2 *online.RPNv2, d *schema.ResourceData) error {
-	server_ids := d.Get("server_ids").([]interface{})
-	if len(server_ids) == 0 {
-		return fmt.Errorf("server_ids cannot be empty")
+func setRPNv2(c online.Client, rpnv2 *online.RPNv2, d *schema.ResourceData, wait time.Duration) error {
+	members, err := expandRPNv2Members(d)
+	if err != nil {
+		return err
+	}
+
+	rpnv2.Members = members
+	if err := c.SetRPNv2(rpnv2, wait); err != nil {
+		return err
 	}
 
-	for _, id := range server_ids {
-		m := &online.Member{}
+	d.SetId(strconv.Itoa(rpnv2.ID))
+
+	return nil
+}
+
+// expandRPNv2Members returns the members from server_ids or the member
+// blocks, the members without vlan get the group one.
+func expandRPNv2Members(d *schema.ResourceData) ([]*online.Member, error) {
+	vlan := d.Get("vlan").(int)
+
+	var members []*online.Member
+	for _, id := range d.Get("server_ids").(*schema.Set).List() {
+		m := &online.Member{VLAN: vlan}
 		m.Linked.ID = id.(int)
-		m.VLAN = d.Get("vlan").(int)
-		rpnv2.Members = append(rpnv2.Members, m)
+		members = append(members, m)
 	}
 
-	if err := c.SetRPNv2(rpnv2, time.Minute); err != nil {
-		return err
+	for _, v := range d.Get("member").([]interface{}) {
+		block := v.(map[string]interface{})
+		m := &online.Member{VLAN: block["vlan"].(int)}
+		m.Linked.ID = block["server_id"].(int)
+		if m.VLAN == 0 {
+			m.VLAN = vlan
+		}
+
+		members = append(members, m)
+	}
+
+	if len(members) == 0 {
+		return nil, fmt.Errorf("server_ids or member cannot be empty")
+	}
+
+	if d.Get("type").(string) == string(online.QinQ) {
+		return members, nil
+	}
+
+	for _, m := range members {
+		if m.VLAN == 0 {
+			return nil, fmt.Errorf("server %d has no vlan, set vlan or the vlan of its member block", m.Linked.ID)
+		}
+	}
+
+	return members, nil
+}
+
+// resourceRPNv2CustomizeDiff rejects vlans on QinQ groups, whose members carry
+// their own VLANs.
+func resourceRPNv2CustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Get("type").(string) != string(online.QinQ) {
+		return nil
+	}
+
+	if d.Get("vlan").(int) != 0 {
+		return fmt.Errorf("vlan cannot be set on %s groups", online.QinQ)
 	}
 
-	d.SetId(rpnv2.Name)
+	for _, v := range d.Get("member").([]interface{}) {
+		block := v.(map[string]interface{})
+		if block["vlan"].(int) != 0 {
+			return fmt.Errorf("member vlan cannot be set on %s groups, got one for server %d",
+				online.QinQ, block["server_id"].(int))
+		}
+	}
 
 	return nil
 }
 
+// suppressDefaultVLANDiff ignores a member vlan left out of the configuration
+// while the member is on the group vlan.
+func suppressDefaultVLANDiff(k, old, new string, d *schema.ResourceData) bool {
+	return (new == "" || new == "0") && old == strconv.Itoa(d.Get("vlan").(int))
+}
+
 func resourceRPNv2Read(d *schema.ResourceData, meta interface{}) error {
-	name := d.Get("name").(string)
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	// the state upgrade gives the id 0 to the groups deleted before it
+	if id == 0 {
+		log.Printf("[DEBUG] RPNv2 group was deleted before the state upgrade")
+		d.SetId("")
+		return nil
+	}
+
 	c := meta.(online.Client)
-	rpnv2, err := c.RPNv2ByName(name)
+	rpnv2, err := c.RPNv2(id)
+	if online.IsNotFound(err) {
+		log.Printf("[DEBUG] RPNv2 group %d was deleted", id)
+		d.SetId("")
+		return nil
+	}
+
 	if err != nil {
 		return err
 	}
 
+	d.Set("name", rpnv2.Name)
+	d.Set("type", string(rpnv2.Type))
+	d.Set("status", rpnv2.Status)
+	d.Set("compatibility_rpn_v1", rpnv2.CompatibilityRPNv1)
+	setRPNv2Members(d, rpnv2)
+
+	return nil
+}
+
+// setRPNv2Members refreshes the members in the form used by the configuration,
+// server_ids or member blocks. Imported groups get member blocks only when
+// their members are on different vlans.
+func setRPNv2Members(d *schema.ResourceData, rpnv2 *online.RPNv2) {
+	blocks := len(d.Get("member").([]interface{})) != 0
+	if !blocks && d.Get("server_ids").(*schema.Set).Len() == 0 {
+		for _, m := range rpnv2.Members {
+			blocks = blocks || rpnv2MemberVLAN(rpnv2, m) != rpnv2MemberVLAN(rpnv2, rpnv2.Members[0])
+		}
+	}
+
+	if blocks {
+		d.Set("member", flattenRPNv2Members(d, rpnv2))
+		return
+	}
+
+	setRPNv2ServerIDs(d, rpnv2)
+}
+
+// setRPNv2ServerIDs refreshes server_ids and vlan from the group members,
+// vlan is unset when the members are on different vlans.
+func setRPNv2ServerIDs(d *schema.ResourceData, rpnv2 *online.RPNv2) {
+	var ids []int
+	vlan := 0
+	for i, m := range rpnv2.Members {
+		ids = append(ids, m.Linked.ID)
+		if i == 0 {
+			vlan = rpnv2MemberVLAN(rpnv2, m)
+		} else if rpnv2MemberVLAN(rpnv2, m) != vlan {
+			vlan = 0
+		}
+	}
+
+	d.Set("server_ids", ids)
+	d.Set("vlan", vlan)
+}
+
+// flattenRPNv2Members returns the member blocks of the group, in the order of
+// the current ones followed by the new members.
+func flattenRPNv2Members(d *schema.ResourceData, rpnv2 *online.RPNv2) []map[string]interface{} {
+	var members []*online.Member
+	known := make(map[int]bool)
+	for _, v := range d.Get("member").([]interface{}) {
+		id := v.(map[string]interface{})["server_id"].(int)
+		if m := rpnv2.MemberByServerID(id); m != nil && !known[id] {
+			members = append(members, m)
+			known[id] = true
+		}
+	}
+
+	for _, m := range rpnv2.Members {
+		if !known[m.Linked.ID] {
+			members = append(members, m)
+		}
+	}
+
+	var blocks []map[string]interface{}
+	for _, m := range members {
+		blocks = append(blocks, map[string]interface{}{
+			"server_id": m.Linked.ID,
+			"vlan":      rpnv2MemberVLAN(rpnv2, m),
+			"status":    m.Status,
+		})
+	}
+
+	return blocks
+}
+
+// rpnv2MemberVLAN returns the vlan of the member, the ones reported for QinQ
+// groups are ignored.
+func rpnv2MemberVLAN(rpnv2 *online.RPNv2, m *online.Member) int {
+	if rpnv2.Type == online.QinQ {
+		return 0
+	}
+
+	return m.VLAN
+}
+
+// resourceRPNv2Import imports a RPNv2 group by its numeric id or its name.
+func resourceRPNv2Import(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	c := meta.(online.Client)
+
+	var rpnv2 *online.RPNv2
+	if id, err := strconv.Atoi(d.Id()); err == nil {
+		rpnv2, err = c.RPNv2(id)
+		if err != nil && !online.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	// numeric names are looked up as well when no group has such id
 	if rpnv2 == nil {
-		return fmt.Errorf("missing RPNv2 group: %q", name)
+		var err error
+		rpnv2, err = c.RPNv2ByName(d.Id())
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	d.SetId(rpnv2.Name)
+	if rpnv2 == nil {
+		return nil, fmt.Errorf("missing RPNv2 group: %q", d.Id())
+	}
 
-	return nil
+	d.SetId(strconv.Itoa(rpnv2.ID))
+	d.Set("name", rpnv2.Name)
+	return []*schema.ResourceData{d}, nil
 }
 
 func resourceRPNv2Delete(d *schema.ResourceData, meta interface{}) error {
@@ -126,15 +388,62 @@ func resourceRPNv2Delete(d *schema.ResourceData, meta interface{}) error {
 		return nil
 	}
 
-	c := meta.(online.Client)
-	rpnv2, err := c.RPNv2ByName(d.Id())
+	id, err := strconv.Atoi(d.Id())
 	if err != nil {
 		return err
 	}
 
-	if rpnv2 == nil {
+	c, cancel := withTimeout(meta.(online.Client), d.Timeout(schema.TimeoutDelete))
+	defer cancel()
+
+	err = c.DeleteRPNv2(id, d.Timeout(schema.TimeoutDelete))
+	if online.IsNotFound(err) {
 		return nil
 	}
 
-	return c.DeleteRPNv2(rpnv2.ID, time.Minute)
+	return err
+}
+
+// resourceRPNv2V0 is the schema of the groups identified by their name
+func resourceRPNv2V0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"vlan": {
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+			"server_ids": {
+				Type:     schema.TypeList,
+				Required: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+		},
+	}
+}
+
+// resourceRPNv2StateUpgradeV0 replaces the name used as id by the group id,
+// groups deleted since get the id 0, so they are removed on the next refresh.
+func resourceRPNv2StateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	name, _ := rawState["id"].(string)
+	rpnv2, err := meta.(online.Client).RPNv2ByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if rpnv2 == nil {
+		log.Printf("[DEBUG] RPNv2 group %q was deleted", name)
+		rawState["id"] = "0"
+		return rawState, nil
+	}
+
+	rawState["id"] = strconv.Itoa(rpnv2.ID)
+	return rawState, nil
 }
@@ -3,9 +3,19 @@ package provider
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/src-d/terraform-provider-online/online"
+	onlinemock "github.com/src-d/terraform-provider-online/online/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestResourceRPNv2Acceptance(t *testing.T) {
@@ -33,8 +43,7 @@ func TestResourceRPNv2Acceptance(t *testing.T) {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("online_rpnv2.test", "name", "terraform-provider-online-acceptance"),
 					resource.TestCheckResourceAttr("online_rpnv2.test", "vlan", "2999"),
-					resource.TestCheckResourceAttr("online_rpnv2.test", "server_ids.#", "1"),
-					resource.TestCheckResourceAttr("online_rpnv2.test", "server_ids.0", TestServerID),
+					testCheckRPNv2ServerIDs("online_rpnv2.test", TestServerID),
 				),
 			},
 			{
@@ -62,9 +71,7 @@ func TestResourceRPNv2Acceptance(t *testing.T) {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("online_rpnv2.test", "name", "terraform-provider-online-acceptance"),
 					resource.TestCheckResourceAttr("online_rpnv2.test", "vlan", "2999"),
-					resource.TestCheckResourceAttr("online_rpnv2.test", "server_ids.#", "2"),
-					resource.TestCheckResourceAttr("online_rpnv2.test", "server_ids.0", TestServerID),
-					resource.TestCheckResourceAttr("online_rpnv2.test", "server_ids.1", TestServerID2),
+					testCheckRPNv2ServerIDs("online_rpnv2.test", TestServerID, TestServerID2),
 				),
 			},
 			{
@@ -92,9 +99,7 @@ func TestResourceRPNv2Acceptance(t *testing.T) {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("online_rpnv2.test", "name", "terraform-provider-online-acceptance"),
 					resource.TestCheckResourceAttr("online_rpnv2.test", "vlan", "2998"),
-					resource.TestCheckResourceAttr("online_rpnv2.test", "server_ids.#", "2"),
-					resource.TestCheckResourceAttr("online_rpnv2.test", "server_ids.0", TestServerID),
-					resource.TestCheckResourceAttr("online_rpnv2.test", "server_ids.1", TestServerID2),
+					testCheckRPNv2ServerIDs("online_rpnv2.test", TestServerID, TestServerID2),
 				),
 			},
 			{
@@ -116,10 +121,492 @@ func TestResourceRPNv2Acceptance(t *testing.T) {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("online_rpnv2.test", "name", "terraform-provider-online-acceptance"),
 					resource.TestCheckResourceAttr("online_rpnv2.test", "vlan", "2998"),
-					resource.TestCheckResourceAttr("online_rpnv2.test", "server_ids.#", "1"),
-					resource.TestCheckResourceAttr("online_rpnv2.test", "server_ids.0", TestServerID2),
+					testCheckRPNv2ServerIDs("online_rpnv2.test", TestServerID2),
 				),
 			},
+			{
+				ResourceName:      "online_rpnv2.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// testCheckRPNv2ServerIDs checks the server_ids set of the resource, in any
+// order
+func testCheckRPNv2ServerIDs(name string, ids ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("not found: %s", name)
+		}
+
+		var got []string
+		for k, v := range rs.Primary.Attributes {
+			if strings.HasPrefix(k, "server_ids.") && k != "server_ids.#" {
+				got = append(got, v)
+			}
+		}
+
+		want := append([]string{}, ids...)
+		sort.Strings(got)
+		sort.Strings(want)
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			return fmt.Errorf("%s: server_ids is %v, expected %v", name, got, want)
+		}
+
+		return nil
+	}
+}
+
+func TestResourceRPNv2TimeoutsUnit(t *testing.T) {
+	rpn := &online.RPNv2{ID: 31, Name: "timeouts", Status: "ACTIVE", Type: online.Standard}
+
+	onlineClientMock.On("RPNv2ByName", "timeouts").Return((*online.RPNv2)(nil), nil).Once()
+	onlineClientMock.On("RPNv2", 31).Return(rpn, nil)
+	onlineClientMock.On("SetRPNv2", mock.AnythingOfType("*online.RPNv2"), 30*time.Minute).
+		Run(setMembers(rpn)).Return(nil).Once()
+	onlineClientMock.On("SetRPNv2", mock.AnythingOfType("*online.RPNv2"), 20*time.Minute).
+		Run(setMembers(rpn)).Return(nil).Once()
+	onlineClientMock.On("DeleteRPNv2", 31, mock.AnythingOfType("time.Duration")).Return(nil)
+
+	config := `
+	resource "online_rpnv2" "test" {
+		name       = "timeouts"
+		vlan       = %d
+		server_ids = [1]
+
+		timeouts {
+			create = "30m"
+			update = "20m"
+		}
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		CheckDestroy: func(*terraform.State) error {
+			onlineClientMock.AssertCalled(t, "DeleteRPNv2", 31, mock.AnythingOfType("time.Duration"))
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, 2000),
+				Check: func(*terraform.State) error {
+					onlineClientMock.AssertCalled(t, "SetRPNv2", mock.AnythingOfType("*online.RPNv2"), 30*time.Minute)
+					return nil
+				},
+			},
+			{
+				Config: fmt.Sprintf(config, 2001),
+				Check: func(*terraform.State) error {
+					onlineClientMock.AssertCalled(t, "SetRPNv2", mock.AnythingOfType("*online.RPNv2"), 20*time.Minute)
+					return nil
+				},
+			},
+		},
+	})
+}
+
+// setMembers copies the members of the group passed to SetRPNv2 into rpn, and
+// sets its id as the API does on creation
+func setMembers(rpn *online.RPNv2) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		r := args.Get(0).(*online.RPNv2)
+		r.ID = rpn.ID
+		rpn.Members = r.Members
+	}
+}
+
+func TestResourceRPNv2ImportUnit(t *testing.T) {
+	rpn := &online.RPNv2{ID: 32, Name: "import", Status: "ACTIVE", Type: online.Standard}
+
+	onlineClientMock.On("RPNv2ByName", "import").Return((*online.RPNv2)(nil), nil).Once()
+	onlineClientMock.On("RPNv2ByName", "import").Return(rpn, nil)
+	onlineClientMock.On("RPNv2", 32).Return(rpn, nil)
+	onlineClientMock.On("RPNv2", 33).Return((*online.RPNv2)(nil), &online.ErrorResponse{Kind: online.ErrNotFound})
+	onlineClientMock.On("RPNv2ByName", "33").Return((*online.RPNv2)(nil), nil)
+	onlineClientMock.On("SetRPNv2", mock.MatchedBy(func(r *online.RPNv2) bool { return r.Name == "import" }),
+		mock.AnythingOfType("time.Duration")).Run(setMembers(rpn)).Return(nil)
+	onlineClientMock.On("DeleteRPNv2", 32, mock.AnythingOfType("time.Duration")).Return(nil)
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "online_rpnv2" "test" {
+					name       = "import"
+					vlan       = 2042
+					server_ids = [2, 1]
+				}
+				`,
+			},
+			{
+				ResourceName:      "online_rpnv2.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "online_rpnv2.test",
+				ImportState:       true,
+				ImportStateId:     "32",
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:  "online_rpnv2.test",
+				ImportState:   true,
+				ImportStateId: "33",
+				ExpectError:   regexp.MustCompile(`missing RPNv2 group: "33"`),
+			},
+		},
+	})
+}
+
+func TestResourceRPNv2MembersUnit(t *testing.T) {
+	rpn := &online.RPNv2{ID: 34, Name: "members", Status: "ACTIVE", Type: online.Standard}
+	activate := func(args mock.Arguments) {
+		setMembers(rpn)(args)
+		for _, m := range rpn.Members {
+			m.Status = "ACTIVE"
+		}
+	}
+	vlans := func(want ...int) interface{} {
+		return mock.MatchedBy(func(r *online.RPNv2) bool {
+			if r.Name != "members" || len(r.Members) != len(want) {
+				return false
+			}
+			for i, m := range r.Members {
+				if m.VLAN != want[i] {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
+	onlineClientMock.On("RPNv2ByName", "members").Return((*online.RPNv2)(nil), nil).Once()
+	onlineClientMock.On("RPNv2", 34).Return(rpn, nil)
+	onlineClientMock.On("SetRPNv2", vlans(2001, 2000), mock.AnythingOfType("time.Duration")).Run(activate).Return(nil)
+	onlineClientMock.On("SetRPNv2", vlans(2001, 2002), mock.AnythingOfType("time.Duration")).Run(activate).Return(nil)
+	onlineClientMock.On("DeleteRPNv2", 34, mock.AnythingOfType("time.Duration")).Return(nil)
+
+	config := `
+	resource "online_rpnv2" "test" {
+		name = "members"
+		vlan = %d
+
+		member {
+			server_id = 1
+			vlan      = 2001
+		}
+
+		member {
+			server_id = 2
+		}
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "online_rpnv2" "test" {
+					name       = "members"
+					vlan       = 2000
+					server_ids = [1]
+
+					member {
+						server_id = 2
+					}
+				}
+				`,
+				ExpectError: regexp.MustCompile(`"member": conflicts with server_ids`),
+			},
+			{
+				Config: fmt.Sprintf(config, 2000),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_rpnv2.test", "member.#", "2"),
+					resource.TestCheckResourceAttr("online_rpnv2.test", "member.0.server_id", "1"),
+					resource.TestCheckResourceAttr("online_rpnv2.test", "member.0.vlan", "2001"),
+					resource.TestCheckResourceAttr("online_rpnv2.test", "member.0.status", "ACTIVE"),
+					resource.TestCheckResourceAttr("online_rpnv2.test", "member.1.server_id", "2"),
+					resource.TestCheckResourceAttr("online_rpnv2.test", "member.1.vlan", "2000"),
+					resource.TestCheckResourceAttr("online_rpnv2.test", "member.1.status", "ACTIVE"),
+				),
+			},
+			{
+				// the member moved to another vlan outside of terraform
+				PreConfig:          func() { rpn.Members[1].VLAN = 2005 },
+				Config:             fmt.Sprintf(config, 2000),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: fmt.Sprintf(config, 2002),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_rpnv2.test", "member.0.vlan", "2001"),
+					resource.TestCheckResourceAttr("online_rpnv2.test", "member.1.vlan", "2002"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceRPNv2RenameUnit(t *testing.T) {
+	rpn := &online.RPNv2{ID: 35, Name: "rename", Status: "ACTIVE", Type: online.Standard}
+	rename := func(args mock.Arguments) {
+		setMembers(rpn)(args)
+		rpn.Name = args.Get(0).(*online.RPNv2).Name
+	}
+
+	onlineClientMock.On("RPNv2ByName", "rename").Return((*online.RPNv2)(nil), nil).Once()
+	onlineClientMock.On("RPNv2", 35).Return(rpn, nil)
+	onlineClientMock.On("SetRPNv2", mock.MatchedBy(func(r *online.RPNv2) bool { return r.ID == 0 && r.Name == "rename" }),
+		mock.AnythingOfType("time.Duration")).Run(rename).Return(nil)
+	onlineClientMock.On("SetRPNv2", mock.MatchedBy(func(r *online.RPNv2) bool { return r.ID == 35 }),
+		mock.AnythingOfType("time.Duration")).Run(rename).Return(nil)
+	onlineClientMock.On("DeleteRPNv2", 35, mock.AnythingOfType("time.Duration")).Return(nil)
+
+	config := `
+	resource "online_rpnv2" "test" {
+		name       = "%s"
+		vlan       = 2000
+		server_ids = [1]
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, "rename"),
+				Check:  resource.TestCheckResourceAttr("online_rpnv2.test", "id", "35"),
+			},
+			{
+				Config: fmt.Sprintf(config, "renamed"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_rpnv2.test", "id", "35"),
+					resource.TestCheckResourceAttr("online_rpnv2.test", "name", "renamed"),
+				),
+			},
+			{
+				// renamed outside of terraform
+				PreConfig:          func() { rpn.Name = "console" },
+				Config:             fmt.Sprintf(config, "renamed"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestResourceRPNv2StateUpgradeV0(t *testing.T) {
+	onlineClientMock.On("RPNv2ByName", "upgrade").Return(&online.RPNv2{ID: 36, Name: "upgrade"}, nil)
+	onlineClientMock.On("RPNv2ByName", "upgrade-missing").Return((*online.RPNv2)(nil), nil)
+
+	state, err := resourceRPNv2StateUpgradeV0(map[string]interface{}{
+		"id":         "upgrade",
+		"name":       "upgrade",
+		"vlan":       2000,
+		"server_ids": []interface{}{1, 2},
+	}, onlineClientMock)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":         "36",
+		"name":       "upgrade",
+		"vlan":       2000,
+		"server_ids": []interface{}{1, 2},
+	}, state)
+
+	// server_ids was a list in the version 0 states and in the version 1 ones
+	// written before it became a set, both are read back as a set
+	v1 := map[string]interface{}{"id": "38", "name": "upgrade-v1", "server_ids": []interface{}{3}}
+	for _, raw := range []map[string]interface{}{state, v1} {
+		r := resourceRPNv2()
+		val, err := schema.JSONMapToStateValue(raw, r.CoreConfigSchema())
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		is, err := r.ShimInstanceStateFromValue(val)
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		ids := r.Data(is).Get("server_ids").(*schema.Set)
+		assert.Equal(t, len(raw["server_ids"].([]interface{})), ids.Len())
+		for _, id := range raw["server_ids"].([]interface{}) {
+			assert.True(t, ids.Contains(id), "%v", id)
+		}
+	}
+
+	// groups deleted since are removed by the next refresh, without asking
+	// the API, the client has no expectation
+	state, err = resourceRPNv2StateUpgradeV0(map[string]interface{}{"id": "upgrade-missing"}, onlineClientMock)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": "0"}, state)
+
+	d := resourceRPNv2().TestResourceData()
+	d.SetId(state["id"].(string))
+	assert.NoError(t, resourceRPNv2Read(d, new(onlinemock.OnlineClientMock)))
+	assert.Equal(t, "", d.Id())
+}
+
+func TestResourceRPNv2RefreshUnit(t *testing.T) {
+	rpn := &online.RPNv2{ID: 37, Name: "refresh", Status: "ACTIVE", Type: online.Standard}
+
+	onlineClientMock.On("RPNv2ByName", "refresh").Return((*online.RPNv2)(nil), nil).Once()
+	onlineClientMock.On("RPNv2", 37).Return(rpn, nil)
+	onlineClientMock.On("SetRPNv2", mock.MatchedBy(func(r *online.RPNv2) bool { return r.Name == "refresh" }),
+		mock.AnythingOfType("time.Duration")).Run(setMembers(rpn)).Return(nil)
+	onlineClientMock.On("DeleteRPNv2", 37, mock.AnythingOfType("time.Duration")).Return(nil)
+
+	config := `
+	resource "online_rpnv2" "test" {
+		name       = "refresh"
+		vlan       = 2000
+		server_ids = [1, 2]
+	}
+	`
+
+	member := func(serverID, vlan int) *online.Member {
+		m := &online.Member{Status: "ACTIVE", VLAN: vlan}
+		m.Linked.ID = serverID
+		return m
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckRPNv2ServerIDs("online_rpnv2.test", "1", "2"),
+					resource.TestCheckResourceAttr("online_rpnv2.test", "status", "ACTIVE"),
+					resource.TestCheckResourceAttr("online_rpnv2.test", "compatibility_rpn_v1", "false"),
+				),
+			},
+			{
+				// the API lists the members in another order
+				PreConfig: func() { rpn.Members = []*online.Member{member(2, 2000), member(1, 2000)} },
+				Config:    config,
+				PlanOnly:  true,
+			},
+			{
+				// a member added in the console
+				PreConfig:          func() { rpn.Members = append(rpn.Members, member(3, 2000)) },
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// a member moved to another vlan in the console
+				PreConfig:          func() { rpn.Members = []*online.Member{member(1, 2000), member(2, 2001)} },
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: func() { rpn.Members = []*online.Member{member(1, 2000), member(2, 2000)} },
+				Config:    config,
+			},
+		},
+	})
+}
+
+func TestResourceRPNv2ReadDeleted(t *testing.T) {
+	onlineClientMock.On("RPNv2", 38).Return((*online.RPNv2)(nil), &online.ErrorResponse{Kind: online.ErrNotFound})
+
+	d := schema.TestResourceDataRaw(t, resourceRPNv2().Schema, map[string]interface{}{"name": "deleted"})
+	d.SetId("38")
+
+	assert.NoError(t, resourceRPNv2Read(d, onlineClientMock))
+	assert.Equal(t, "", d.Id())
+}
+
+func TestResourceRPNv2QinQUnit(t *testing.T) {
+	rpn := &online.RPNv2{ID: 39, Name: "qinq", Status: "ACTIVE", Type: online.QinQ}
+	setGroup := func(args mock.Arguments) {
+		setMembers(rpn)(args)
+		rpn.CompatibilityRPNv1 = args.Get(0).(*online.RPNv2).CompatibilityRPNv1
+	}
+
+	onlineClientMock.On("RPNv2ByName", "qinq").Return((*online.RPNv2)(nil), nil).Once()
+	onlineClientMock.On("RPNv2", 39).Return(rpn, nil)
+	onlineClientMock.On("SetRPNv2", mock.MatchedBy(func(r *online.RPNv2) bool {
+		return r.Name == "qinq" && r.Type == online.QinQ && r.Members[0].VLAN == 0
+	}), mock.AnythingOfType("time.Duration")).Run(setGroup).Return(nil)
+	onlineClientMock.On("DeleteRPNv2", 39, mock.AnythingOfType("time.Duration")).Return(nil)
+
+	config := `
+	resource "online_rpnv2" "test" {
+		name                 = "qinq"
+		type                 = "QINQ"
+		server_ids           = [1, 2]
+		compatibility_rpn_v1 = %t
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "online_rpnv2" "test" {
+					name       = "qinq"
+					type       = "SPECIAL"
+					vlan       = 2000
+					server_ids = [1]
+				}
+				`,
+				ExpectError: regexp.MustCompile(`type must be one of STANDARD, QINQ, DEMO, got: "SPECIAL"`),
+			},
+			{
+				Config: `
+				resource "online_rpnv2" "test" {
+					name       = "qinq"
+					type       = "QINQ"
+					vlan       = 2000
+					server_ids = [1]
+				}
+				`,
+				ExpectError: regexp.MustCompile(`vlan cannot be set on QINQ groups`),
+			},
+			{
+				Config: `
+				resource "online_rpnv2" "test" {
+					name = "qinq"
+					type = "QINQ"
+
+					member {
+						server_id = 1
+						vlan      = 2000
+					}
+				}
+				`,
+				ExpectError: regexp.MustCompile(`member vlan cannot be set on QINQ groups, got one for server 1`),
+			},
+			{
+				Config: fmt.Sprintf(config, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_rpnv2.test", "type", "QINQ"),
+					resource.TestCheckResourceAttr("online_rpnv2.test", "vlan", "0"),
+					resource.TestCheckResourceAttr("online_rpnv2.test", "compatibility_rpn_v1", "true"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, false),
+				Check:  resource.TestCheckResourceAttr("online_rpnv2.test", "compatibility_rpn_v1", "false"),
+			},
 		},
 	})
 }
@@ -1,46 +1,85 @@
 package provider
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/src-d/terraform-provider-online/online"
 )
 
 func resourceServer() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"server_id": &schema.Schema{
+			Type:        schema.TypeInt,
+			Required:    true,
+			Description: "server id",
+		},
+		"hostname": &schema.Schema{
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "server hostname",
+		},
+		"public_interface": &schema.Schema{
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Computed:    true,
+			Elem:        resourceInterface(),
+			Description: "Public interface properties",
+		},
+		"private_interface": &schema.Schema{
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Computed:    true,
+			Elem:        resourceInterface(),
+			Description: "Private interface properties",
+		},
+	}
+
+	for k, v := range serverComputedSchema() {
+		s[k] = v
+	}
+
+	s["power_state"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "Desired power state of the server, on or off, left untouched if not set",
+		ValidateFunc: validateStringIn("on", "off"),
+	}
+	s["reboot_trigger"] = &schema.Schema{
+		Type:        schema.TypeMap,
+		Optional:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "Arbitrary values, any change reboots the server",
+	}
+	s["installed_os"] = installedOSSchema()
+	s["os"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		ForceNew:    true,
+		MaxItems:    1,
+		Elem:        resourceOSInstall(),
+		Description: "Operating system to install, any change reinstalls the server erasing all its data",
+	}
+
 	return &schema.Resource{
 		Create: resourceServerCreate,
-		Update: resourceServerCreate,
+		Update: resourceServerUpdate,
 		Read:   resourceServerRead,
 		Delete: resourceServerDelete,
 
-		Schema: map[string]*schema.Schema{
-			"server_id": &schema.Schema{
-				Type:        schema.TypeInt,
-				Required:    true,
-				Description: "server id",
-			},
-			"hostname": &schema.Schema{
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "server hostname",
-			},
-			"public_interface": &schema.Schema{
-				Type:        schema.TypeMap,
-				Optional:    true,
-				Computed:    true,
-				Elem:        resourceInterface(),
-				Description: "Public interface properties",
-			},
-			"private_interface": &schema.Schema{
-				Type:        schema.TypeMap,
-				Optional:    true,
-				Computed:    true,
-				Elem:        resourceInterface(),
-				Description: "Private interface properties",
-			},
+		Importer: &schema.ResourceImporter{
+			State: resourceServerImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(time.Hour),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 		},
+
+		Schema: s,
 	}
 }
 
@@ -66,12 +105,193 @@ func resourceInterface() *schema.Resource {
 	}
 }
 
+func resourceOSInstall() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"os_id": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Id of the operating system, as listed for the server.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Name of the operating system, used when os_id is not set.",
+			},
+			"version": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Version of the operating system, used with name.",
+			},
+			"hostname": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Hostname set during the installation, defaults to the server hostname.",
+			},
+			"user_login": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Login of the user created during the installation.",
+			},
+			"user_password": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Sensitive:   true,
+				Description: "Password of the user created during the installation.",
+			},
+			"root_password": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Sensitive:   true,
+				Description: "Password of the root user.",
+			},
+			"panel_password": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Sensitive:   true,
+				Description: "Password of the control panel, for the operating systems shipping one.",
+			},
+			"ssh_key_ids": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Ids of the SSH keys, as registered in the console, authorized for the user.",
+			},
+			"partitioning_template": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Reference of the partitioning template, defaults to the offer layout.",
+			},
+			"partition": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        resourcePartition(true),
+				Description: "Custom partitions, in disk order, conflicts with partitioning_template.",
+			},
+		},
+	}
+}
+
+// resourceServerImport imports a server by its numeric id.
+func resourceServerImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return nil, fmt.Errorf("Invalid server id %q, expected a number", d.Id())
+	}
+
+	d.Set("server_id", id)
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceServerDelete(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
 func resourceServerCreate(d *schema.ResourceData, meta interface{}) error {
-	c := meta.(online.Client)
+	// the install and the power changes share the create timeout
+	c, cancel := withTimeout(meta.(online.Client), d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
+	if _, ok := d.GetOk("os"); ok {
+		if err := installServer(c, d); err != nil {
+			return err
+		}
+	}
+
+	if err := updateServer(c, d); err != nil {
+		return err
+	}
+
+	return resourceServerRead(d, meta)
+}
+
+func installServer(c online.Client, d *schema.ResourceData) error {
+	serverID := d.Get("server_id").(int)
+	install := &online.Install{
+		OSID:                 d.Get("os.0.os_id").(int),
+		Hostname:             d.Get("os.0.hostname").(string),
+		UserLogin:            d.Get("os.0.user_login").(string),
+		UserPassword:         d.Get("os.0.user_password").(string),
+		RootPassword:         d.Get("os.0.root_password").(string),
+		PanelPassword:        d.Get("os.0.panel_password").(string),
+		PartitioningTemplate: d.Get("os.0.partitioning_template").(string),
+	}
+
+	if install.Hostname == "" {
+		install.Hostname = d.Get("hostname").(string)
+	}
+
+	for _, key := range d.Get("os.0.ssh_key_ids").([]interface{}) {
+		install.SSHKeys = append(install.SSHKeys, key.(string))
+	}
+
+	install.Partitions = expandPartitions(d.Get("os.0.partition").([]interface{}))
+	if len(install.Partitions) != 0 {
+		if install.PartitioningTemplate != "" {
+			return fmt.Errorf("os partition and partitioning_template can't be used together")
+		}
+
+		s, err := c.Server(serverID)
+		if err != nil {
+			return err
+		}
+
+		if err := online.ValidatePartitions(install.Partitions, s); err != nil {
+			return err
+		}
+	}
+
+	if install.OSID == 0 {
+		os, err := findOperatingSystem(c, serverID, d.Get("os.0.name").(string), d.Get("os.0.version").(string))
+		if err != nil {
+			return err
+		}
+
+		install.OSID = os.ID
+	}
+
+	return c.InstallServer(serverID, install, d.Timeout(schema.TimeoutCreate))
+}
+
+// findOperatingSystem returns the newest operating system installable on the
+// server with the given name and, if not empty, version.
+func findOperatingSystem(c online.Client, serverID int, name, version string) (*online.OperatingSystem, error) {
+	if name == "" {
+		return nil, fmt.Errorf("Need either an os_id or a name to install server %d", serverID)
+	}
+
+	list, err := c.OperatingSystems(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectOperatingSystem(list, &online.OperatingSystem{Name: name, Version: version})
+}
+
+func resourceServerUpdate(d *schema.ResourceData, meta interface{}) error {
+	c, cancel := withTimeout(meta.(online.Client), d.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+
+	if err := updateServer(c, d); err != nil {
+		return err
+	}
+
+	return resourceServerRead(d, meta)
+}
+
+// updateServer applies the hostname, reverse and power changes.
+func updateServer(c online.Client, d *schema.ResourceData) error {
 	s, err := getServer(c, d)
 	if err != nil {
 		return err
@@ -81,7 +301,30 @@ func resourceServerCreate(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	return resourceServerRead(d, meta)
+	return updatePowerIfNeeded(c, s, d)
+}
+
+func updatePowerIfNeeded(c online.Client, s *online.Server, d *schema.ResourceData) error {
+	id := d.Get("server_id").(int)
+	powerWait := d.Timeout(schema.TimeoutUpdate)
+	if d.IsNewResource() {
+		powerWait = d.Timeout(schema.TimeoutCreate)
+	}
+
+	power := strings.ToUpper(d.Get("power_state").(string))
+	switch {
+	case power == online.PowerOn && s.Power != online.PowerOn:
+		return c.BootServer(id, powerWait)
+	case power == online.PowerOff && s.Power != online.PowerOff:
+		return c.ShutdownServer(id, "power_state set to off", powerWait)
+	}
+
+	// the trigger is only meant to reboot servers already managed
+	if d.IsNewResource() || !d.HasChange("reboot_trigger") || s.Power == online.PowerOff {
+		return nil
+	}
+
+	return c.RebootServer(id, "reboot_trigger changed", powerWait)
 }
 
 func updateServerIfNeeded(c online.Client, s *online.Server, d *schema.ResourceData) error {
@@ -115,6 +358,12 @@ func resourceServerRead(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	applyServer(s, d)
+
+	// the power state is only tracked when managed, to avoid a diff otherwise
+	if d.Get("power_state").(string) != "" {
+		d.Set("power_state", strings.ToLower(s.Power))
+	}
+
 	return nil
 }
 
@@ -146,6 +395,9 @@ func applyServer(s *online.Server, d *schema.ResourceData) {
 		}
 	}
 
+	d.Set("hostname", s.Hostname)
 	d.Set("public_interface", public)
 	d.Set("private_interface", private)
+	d.Set("installed_os", flattenOS(s.OS))
+	setServerAttributes(s, d)
 }
@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/src-d/terraform-provider-online/online"
+)
+
+// bootModeWait is how long boot mode changes are waited for
+const bootModeWait = 10 * time.Minute
+
+func resourceServerRescue() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceServerRescueCreate,
+		Read:   resourceServerRescueRead,
+		Delete: resourceServerRescueDelete,
+
+		Schema: map[string]*schema.Schema{
+			"server_id": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				ForceNew:    true,
+				Description: "id of the server to boot in rescue mode",
+			},
+			"image": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "rescue image to boot, as returned by the online_rescue_image data source",
+			},
+			"login": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "login of the rescue system",
+			},
+			"password": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "password of the rescue system",
+			},
+			"ip": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "address to connect to the rescue system",
+			},
+			"protocol": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "protocol to connect to the rescue system, eg: ssh",
+			},
+		},
+	}
+}
+
+func resourceServerRescueCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+	id := d.Get("server_id").(int)
+
+	credentials, err := c.BootRescueMode(id, d.Get("image").(string))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(strconv.Itoa(id))
+	d.Set("login", credentials.Login)
+	d.Set("password", credentials.Password)
+	d.Set("ip", credentials.IP)
+	d.Set("protocol", credentials.Protocol)
+
+	if err := c.WaitBootMode(id, online.BootModeRescue, bootModeWait); err != nil {
+		return err
+	}
+
+	return resourceServerRescueRead(d, meta)
+}
+
+func resourceServerRescueRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+	s, err := c.Server(d.Get("server_id").(int))
+	if online.IsNotFound(err) {
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// the server left the rescue mode outside of terraform
+	if s.BootMode != online.BootModeRescue {
+		d.SetId("")
+	}
+
+	return nil
+}
+
+func resourceServerRescueDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(online.Client)
+	id := d.Get("server_id").(int)
+
+	if err := c.BootNormalMode(id); err != nil {
+		return err
+	}
+
+	return c.WaitBootMode(id, online.BootModeNormal, bootModeWait)
+}
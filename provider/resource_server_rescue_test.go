@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/src-d/terraform-provider-online/online"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResourceServerRescue(t *testing.T) {
+	s := testServer("rescue")
+	s.ID = 987
+
+	setBootMode := func(mode string) func(mock.Arguments) {
+		return func(mock.Arguments) { s.BootMode = mode }
+	}
+
+	onlineClientMock.On("Server", 987).Return(s, nil)
+	onlineClientMock.On("BootRescueMode", 987, "ubuntu-18.04_amd64").
+		Run(setBootMode(online.BootModeRescue)).
+		Return(&online.RescueCredentials{
+			Login:    "rescue",
+			Password: "secret",
+			IP:       "1.2.3.4",
+			Protocol: "ssh",
+		}, nil)
+	onlineClientMock.On("BootNormalMode", 987).Run(setBootMode(online.BootModeNormal)).Return(nil)
+	onlineClientMock.On("WaitBootMode", 987, online.BootModeRescue, 10*time.Minute).Return(nil)
+	onlineClientMock.On("WaitBootMode", 987, online.BootModeNormal, 10*time.Minute).Return(nil)
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		CheckDestroy: func(*terraform.State) error {
+			onlineClientMock.AssertCalled(t, "WaitBootMode", 987, online.BootModeNormal, 10*time.Minute)
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "online_server_rescue" "test" {
+					server_id = 987
+					image     = "ubuntu-18.04_amd64"
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_server_rescue.test", "id", "987"),
+					resource.TestCheckResourceAttr("online_server_rescue.test", "login", "rescue"),
+					resource.TestCheckResourceAttr("online_server_rescue.test", "password", "secret"),
+					resource.TestCheckResourceAttr("online_server_rescue.test", "ip", "1.2.3.4"),
+					resource.TestCheckResourceAttr("online_server_rescue.test", "protocol", "ssh"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceServerRescueLeftOutside(t *testing.T) {
+	s := testServer("rescue")
+	s.ID = 988
+
+	onlineClientMock.On("Server", 988).Return(s, nil)
+	onlineClientMock.On("BootRescueMode", 988, "ubuntu-18.04_amd64").
+		Run(func(mock.Arguments) { s.BootMode = online.BootModeRescue }).
+		Return(&online.RescueCredentials{}, nil)
+	onlineClientMock.On("WaitBootMode", 988, online.BootModeRescue, 10*time.Minute).Return(nil)
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "online_server_rescue" "test" {
+					server_id = 988
+					image     = "ubuntu-18.04_amd64"
+				}
+			`,
+				Check: resource.TestCheckResourceAttr("online_server_rescue.test", "id", "988"),
+			},
+			{
+				// the server was rebooted in normal mode outside of terraform
+				PreConfig: func() { s.BootMode = online.BootModeNormal },
+				Config: `
+				resource "online_server_rescue" "test" {
+					server_id = 988
+					image     = "ubuntu-18.04_amd64"
+				}
+			`,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
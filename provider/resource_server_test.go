@@ -2,15 +2,25 @@ package provider
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
 	"github.com/src-d/terraform-provider-online/online"
+	"github.com/stretchr/testify/mock"
 )
 
-func setupMock() {
-	onlineClientMock.On("SetServer", &online.Server{
-		Hostname: "mock",
+func testServer(hostname string) *online.Server {
+	s := &online.Server{
+		Hostname: hostname,
+		Offer:    "Dedibox XC",
+		OS:       &online.OS{Name: "Ubuntu", Version: "18.04"},
+		Power:    "ON",
+		BootMode: "normal",
+		Support:  "Basic service level",
+		Location: &online.Location{Datacenter: "DC3", Rack: "E11"},
 		IP: []*online.Interface{
 			&online.Interface{
 				Address: "1.2.3.4",
@@ -24,22 +34,22 @@ func setupMock() {
 				Type:    online.Private,
 			},
 		},
-	}).Return(nil)
-	onlineClientMock.On("Server", 123).Return(&online.Server{
-		IP: []*online.Interface{
-			&online.Interface{
-				Address: "1.2.3.4",
-				MAC:     "aa:bb:cc:dd:ee:ff",
-				Reverse: "my.dns.address",
-				Type:    online.Public,
-			},
-			&online.Interface{
-				Address: "10.2.3.4",
-				MAC:     "00:bb:cc:dd:ee:ff",
-				Type:    online.Private,
-			},
-		},
-	}, nil)
+		Disks: []online.Ref{{Ref: "/api/v1/server/hardware/disk/1"}, {Ref: "/api/v1/server/hardware/disk/2"}},
+		DriveArrays: []*online.DriveArray{{
+			Disks:          []online.Ref{{Ref: "/api/v1/server/hardware/disk/1"}, {Ref: "/api/v1/server/hardware/disk/2"}},
+			RaidController: online.Ref{Ref: "/api/v1/server/hardware/raidController/1"},
+			RaidLevel:      "RAID1",
+		}},
+		RaidControllers: []online.Ref{{Ref: "/api/v1/server/hardware/raidController/1"}},
+	}
+
+	s.Network.Ipfo = []string{"5.6.7.8"}
+	return s
+}
+
+func setupMock() {
+	onlineClientMock.On("SetServer", testServer("mock")).Return(nil)
+	onlineClientMock.On("Server", 123).Return(testServer(""), nil)
 }
 
 func TestResourceServerUnit(t *testing.T) {
@@ -63,7 +73,29 @@ func TestResourceServerUnit(t *testing.T) {
 				resource.TestCheckResourceAttr("online_server.test", "public_interface.dns", "my.dns.address"),
 				resource.TestCheckResourceAttr("online_server.test", "private_interface.address", "10.2.3.4"),
 				resource.TestCheckResourceAttr("online_server.test", "private_interface.mac", "00:bb:cc:dd:ee:ff"),
+				resource.TestCheckResourceAttr("online_server.test", "offer", "Dedibox XC"),
+				resource.TestCheckResourceAttr("online_server.test", "installed_os.name", "Ubuntu"),
+				resource.TestCheckResourceAttr("online_server.test", "power", "ON"),
+				resource.TestCheckResourceAttr("online_server.test", "support", "Basic service level"),
+				resource.TestCheckResourceAttr("online_server.test", "location.datacenter", "DC3"),
+				resource.TestCheckResourceAttr("online_server.test", "failover_ips.#", "1"),
+				resource.TestCheckResourceAttr("online_server.test", "failover_ips.0", "5.6.7.8"),
+				resource.TestCheckResourceAttr("online_server.test", "disks.#", "2"),
+				resource.TestCheckResourceAttr("online_server.test", "drive_arrays.#", "1"),
+				resource.TestCheckResourceAttr("online_server.test", "drive_arrays.0.raid_level", "RAID1"),
+				resource.TestCheckResourceAttr("online_server.test", "drive_arrays.0.disks.#", "2"),
+				resource.TestCheckResourceAttr("online_server.test", "raid_controllers.0",
+					"/api/v1/server/hardware/raidController/1"),
 			),
+		}, {
+			ResourceName:      "online_server.test",
+			ImportState:       true,
+			ImportStateVerify: true,
+		}, {
+			ResourceName:  "online_server.test",
+			ImportState:   true,
+			ImportStateId: "mock",
+			ExpectError:   regexp.MustCompile("Invalid server id"),
 		}},
 	})
 }
@@ -98,6 +130,260 @@ func TestResourceServerAcceptance(t *testing.T) {
 					resource.TestCheckResourceAttr("online_server.test", "server_id", TestServerID),
 				),
 			},
+			{
+				ResourceName:      "online_server.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceServerInstallUnit(t *testing.T) {
+	installed := testServer("installed")
+	installed.ID = 321
+	onlineClientMock.On("Server", 321).Return(installed, nil)
+	onlineClientMock.On("OperatingSystems", 321).Return([]*online.OperatingSystem{
+		{ID: 301, Name: "Debian", Version: "9"},
+		{ID: 302, Name: "Ubuntu", Version: "18.04"},
+	}, nil)
+	onlineClientMock.On("InstallServer", 321, &online.Install{
+		OSID:         302,
+		Hostname:     "installed",
+		UserLogin:    "admin",
+		UserPassword: "secret",
+		SSHKeys:      []string{"key-1"},
+	}, time.Hour).Return(nil).Once()
+	onlineClientMock.On("InstallServer", 321, &online.Install{
+		OSID:     301,
+		Hostname: "custom",
+	}, time.Hour).Return(nil).Once()
+	onlineClientMock.On("InstallServer", 321, &online.Install{
+		OSID:     301,
+		Hostname: "installed",
+		Partitions: []*online.Partition{
+			{RaidLevel: "RAID1", MountPoint: "/", FileSystem: "ext4", Size: 20000},
+			{RaidLevel: "RAID1", FileSystem: "swap", Size: 4096},
+			{RaidLevel: "RAID1", MountPoint: "/srv", FileSystem: "xfs"},
+		},
+	}, time.Hour).Return(nil).Once()
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "online_server" "test" {
+					server_id = 321
+					hostname = "installed"
+
+					os {
+						name          = "ubuntu"
+						user_login    = "admin"
+						user_password = "secret"
+						ssh_key_ids   = ["key-1"]
+					}
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_server.test", "os.0.name", "ubuntu"),
+					resource.TestCheckResourceAttr("online_server.test", "installed_os.name", "Ubuntu"),
+				),
+			},
+			{
+				Config: `
+				resource "online_server" "test" {
+					server_id = 321
+					hostname = "installed"
+
+					os {
+						os_id    = 301
+						hostname = "custom"
+					}
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_server.test", "os.0.os_id", "301"),
+					func(s *terraform.State) error {
+						onlineClientMock.AssertNumberOfCalls(t, "InstallServer", 2)
+						return nil
+					},
+				),
+			},
+			{
+				Config: `
+				resource "online_server" "test" {
+					server_id = 321
+					hostname = "installed"
+
+					os {
+						os_id = 301
+
+						partition {
+							raid_level  = "RAID1"
+							mount_point = "/"
+							file_system = "ext4"
+							size        = 20000
+						}
+
+						partition {
+							raid_level  = "RAID1"
+							file_system = "swap"
+							size        = 4096
+						}
+
+						partition {
+							raid_level  = "RAID1"
+							mount_point = "/srv"
+							file_system = "xfs"
+						}
+					}
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_server.test", "os.0.partition.#", "3"),
+					func(s *terraform.State) error {
+						onlineClientMock.AssertNumberOfCalls(t, "InstallServer", 3)
+						return nil
+					},
+				),
+			},
+			{
+				Config: `
+				resource "online_server" "test" {
+					server_id = 321
+					hostname = "installed"
+
+					os {
+						os_id = 301
+
+						partition {
+							raid_level  = "RAID5"
+							mount_point = "/"
+							file_system = "ext4"
+						}
+					}
+				}
+			`,
+				ExpectError: regexp.MustCompile(`RAID5 requires 3 disks, drive array 0 of server 321 has 2`),
+			},
+			{
+				Config: `
+				resource "online_server" "test" {
+					server_id = 321
+					hostname = "installed"
+
+					os {
+						name = "windows"
+					}
+				}
+			`,
+				ExpectError: regexp.MustCompile(`No operating system found for requirements`),
+			},
+		},
+	})
+}
+
+func TestResourceServerPowerUnit(t *testing.T) {
+	s := testServer("power")
+	s.ID = 654
+	s.Power = online.PowerOff
+
+	setPower := func(power string) func() {
+		return func() { s.Power = power }
+	}
+	run := func(f func()) func(mock.Arguments) {
+		return func(mock.Arguments) { f() }
+	}
+
+	onlineClientMock.On("Server", 654).Return(s, nil)
+	onlineClientMock.On("BootServer", 654, time.Hour).Run(run(setPower(online.PowerOn))).Return(nil)
+	onlineClientMock.On("RebootServer", 654, "reboot_trigger changed", 10*time.Minute).Return(nil)
+	onlineClientMock.On("ShutdownServer", 654, "power_state set to off", 10*time.Minute).
+		Run(run(setPower(online.PowerOff))).Return(nil)
+
+	resource.Test(t, resource.TestCase{
+		Providers:  testMockProviders,
+		IsUnitTest: true,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "online_server" "test" {
+					server_id   = 654
+					hostname    = "power"
+					power_state = "on"
+
+					reboot_trigger = {
+						kernel = "4.15"
+					}
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_server.test", "power_state", "on"),
+					resource.TestCheckResourceAttr("online_server.test", "power", "ON"),
+					func(*terraform.State) error {
+						onlineClientMock.AssertNumberOfCalls(t, "BootServer", 1)
+						onlineClientMock.AssertNumberOfCalls(t, "RebootServer", 0)
+						return nil
+					},
+				),
+			},
+			{
+				Config: `
+				resource "online_server" "test" {
+					server_id   = 654
+					hostname    = "power"
+					power_state = "on"
+
+					reboot_trigger = {
+						kernel = "4.18"
+					}
+				}
+			`,
+				Check: func(*terraform.State) error {
+					onlineClientMock.AssertNumberOfCalls(t, "RebootServer", 1)
+					return nil
+				},
+			},
+			{
+				PreConfig: setPower(online.PowerOff),
+				Config: `
+				resource "online_server" "test" {
+					server_id   = 654
+					hostname    = "power"
+					power_state = "on"
+
+					reboot_trigger = {
+						kernel = "4.18"
+					}
+				}
+			`,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: setPower(online.PowerOn),
+				Config: `
+				resource "online_server" "test" {
+					server_id   = 654
+					hostname    = "power"
+					power_state = "off"
+
+					reboot_trigger = {
+						kernel = "4.18"
+					}
+				}
+			`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("online_server.test", "power_state", "off"),
+					resource.TestCheckResourceAttr("online_server.test", "power", "OFF"),
+					func(*terraform.State) error {
+						onlineClientMock.AssertNumberOfCalls(t, "ShutdownServer", 1)
+						return nil
+					},
+				),
+			},
 		},
 	})
 }